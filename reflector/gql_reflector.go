@@ -1,7 +1,6 @@
 package reflector
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 
@@ -11,115 +10,326 @@ import (
 const (
 	// GqlExcludeTagName is the name of the struct field tag to use for exclusions.
 	GqlExcludeTagName = "gqlexclude"
+	// GqlNullTagName is the name of the struct field tag to use for
+	// overriding the nullability of a field.
+	GqlNullTagName = "gqlnull"
+	// GqlMapTagName is the name of the struct field tag to use for choosing
+	// how map fields are reflected.
+	GqlMapTagName = "gqlmap"
+	// GqlDescTagName is the name of the struct field tag to use for
+	// descriptions.
+	GqlDescTagName = "gqldesc"
+	// GqlDefaultTagName is the name of the struct field tag to use for the
+	// default values of arguments.
+	GqlDefaultTagName = "gqldefault"
+	// GqlDeprecatedTagName is the name of the struct field tag to use for
+	// the deprecation reasons of fields.
+	GqlDeprecatedTagName = "gqldeprecated"
+	// GqlTimeTagName is the name of the struct field tag to use for the
+	// formats of time fields.
+	GqlTimeTagName = "gqltime"
+	// GqlIntTagName is the name of the struct field tag to use for the
+	// scalars of integer fields.
+	GqlIntTagName = "gqlint"
 )
 
 // ReflectType is a shorthand method for invoking ReflectTypeFq.
 // It derives the most basic fields from the given instance (typically a struct)
 // such as the name of the struct, and uses the default type mapping and no
 // exclude tags at all.
-func ReflectType(instance interface{}) graphql.Type {
+func ReflectType(instance interface{}, opts ...Option) graphql.Type {
 	if instance == nil {
 		panic("Cannot infer type of nil instance")
 	}
 	t := reflect.TypeOf(instance)
 	return ReflectTypeFq(
-		GqlName(t.Name()),
+		GqlName(indirectType(t).Name()),
 		t,
 		GetDefaultTypeMap(),
 		ExcludeFieldTag(""),
+		opts...,
 	)
 }
 
 // ReflectTypeWithTypeMap is a shorthand to invoking ReflectTypeEq with
 // reasonable default values and your own provided type map.
-func ReflectTypeWithTypeMap(instance interface{}, typeMap TypeMap) graphql.Type {
+func ReflectTypeWithTypeMap(
+	instance interface{},
+	typeMap TypeMap,
+	opts ...Option,
+) graphql.Type {
 	if instance == nil {
 		panic("Cannot infer type of nil instance")
 	}
 	t := reflect.TypeOf(instance)
 	return ReflectTypeFq(
-		GqlName(t.Name()),
+		GqlName(indirectType(t).Name()),
 		t,
 		typeMap,
 		ExcludeFieldTag(""),
+		opts...,
 	)
 }
 
 // ReflectTypeFq (Reflect Type Fully Qualified) returns a Graphql type that
-// represents the go reflect.Type structure (recorsively)
+// represents the go reflect.Type structure (recorsively).
+// Graphql types are named after their go types (see TypeNamer), name is only
+// used for types that have no name of their own, such as anonymous structs.
 func ReflectTypeFq(
 	name GqlName,
 	t reflect.Type,
 	typeMap TypeMap,
 	exclude ExcludeFieldTag,
+	opts ...Option,
 ) graphql.Type {
-	gqlType := getGqlType(t, typeMap)
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectType(name, t)
+}
+
+// ReflectFieldsFq returns a `graphql.Fields` map of the t struct.
+// t must of a struct
+func ReflectFieldsFq(
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) graphql.Fields {
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectFields(r.namer(t, GqlName(t.Name())), t)
+}
+
+// ReflectFieldFq returns a Graphql field that represents
+// the go reflect.Field (recorsively)
+func ReflectFieldFq(
+	name GqlName,
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) *graphql.Field {
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectField(name, t, name)
+}
+
+// reflection holds everything needed throughout a single reflection of a go
+// type into a graphql type
+type reflection struct {
+	options
+	// The problems found so far, if the reflection collects them rather than
+	// panic (see collectErrors)
+	errs ReflectionErrors
+	// The segments of the go path to the type being reflected
+	path []string
+	// The go types reached so far, whose names were claimed (see claimNames),
+	// and the type that claims each go name
+	claimed map[reflect.Type]bool
+	claims  map[string]reflect.Type
+	// Whether the reflection found a problem with the go types
+	failed bool
+	// The additions of the reflection to the type cache
+	additions []cacheAddition
+}
+
+// cacheAddition is a graphql type, or a name, added to the type cache for the
+// go type t
+type cacheAddition struct {
+	t    reflect.Type
+	undo func()
+}
+
+// Start a new reflection with the given type map, exclude tag and options.
+// The reflection holds its type cache until done is called.
+func newReflection(
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts []Option,
+) *reflection {
+	return newReflector(typeMap, exclude, opts).start()
+}
+
+// Release the type cache held by the reflection. The types added to the cache
+// by a reflection that failed are removed, so they're reflected again, and
+// fail again, rather than be taken from the cache as they are. So are the
+// unnamed types added to a default cache, which are named after their path
+// within this reflection.
+func (r *reflection) done() {
+	for i := len(r.additions) - 1; i >= 0; i-- {
+		if a := r.additions[i]; r.failed || (r.cache.isDefault && a.t.Name() == "") {
+			a.undo()
+		}
+	}
+	r.cache.mu.Unlock()
+}
+
+// Record that the reflection added to the type cache for the go type t, and
+// how to undo it
+func (r *reflection) added(t reflect.Type, undo func()) {
+	r.additions = append(r.additions, cacheAddition{t, undo})
+}
+
+// Reflect the go type t. name is the name to use for t if it has no name of
+// its own.
+func (r *reflection) reflectType(name GqlName, t reflect.Type) graphql.Type {
+	r.claimNames(t)
+	if m := r.marshalingOf(t); m != notMarshaled {
+		return m.scalar()
+	}
+	gqlType := getGqlType(t, r.typeMap)
 	if gqlType != nil {
+		r.checkIntFits(t, gqlType)
 		return gqlType
 	}
+	if enum := r.reflectEnum(t); enum != nil {
+		return enum
+	}
+	if isValuer(t) {
+		return JSON
+	}
 	switch t.Kind() {
 	case reflect.String:
 		return graphql.String
 	case reflect.Interface:
-		// for interfaces assume type String. Correct assumption?
+		if abstract := r.reflectPolymorphic(name, t); abstract != nil {
+			return abstract
+		}
+		// for other interfaces assume type String. Correct assumption?
 		return graphql.String
 	case reflect.Bool:
 		return graphql.Boolean
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
 		reflect.Uint64:
+		r.checkIntFits(t, graphql.Int)
 		return graphql.Int
 	case reflect.Float32, reflect.Float64:
 		return graphql.Float
 	case reflect.Struct:
-		fields := ReflectFieldsFq(t, typeMap, exclude)
-		return graphql.NewObject(graphql.ObjectConfig{
-			Name:   generateGqlOTypeName(name),
-			Fields: fields,
-		})
+		return r.reflectObject(name, t)
 	case reflect.Slice, reflect.Array:
-		return graphql.NewList(ReflectTypeFq(name, t.Elem(), typeMap, exclude))
+		defer r.at("[]")()
+		return graphql.NewList(r.reflectType(name, t.Elem()))
+	case reflect.Ptr:
+		// Pointers are nullable, which is the default for graphql types, so
+		// simply use the type of the element
+		return r.reflectType(name, t.Elem())
+	case reflect.Map:
+		return graphql.NewList(r.reflectMapEntry(name, t))
 	case reflect.Invalid:
-		panic(fmt.Sprintf("Invalid GQL kind %s. Field: %s", t.Kind(), t.Name()))
-	case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.UnsafePointer:
-		panic(fmt.Sprintf("Unsupported GQL kind %s. Field: %s", t.Kind(), t.Name()))
+		r.fail(t, "Invalid GQL kind %s. Field: %s", t.Kind(), t.Name())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		r.fail(t, "Unsupported GQL kind %s. Field: %s", t.Kind(), t.Name())
 	default:
-		panic(fmt.Sprintf("Unknown GO kind %s. Field: %s", t.Kind(), t.Name()))
+		r.fail(t, "Unknown GO kind %s. Field: %s", t.Kind(), t.Name())
 	}
+	// Carry on collecting errors
+	return graphql.String
 }
 
-// ReflectFieldsFq returns a `graphql.Fields` map of the t struct.
-// t must of a struct
-func ReflectFieldsFq(
-	t reflect.Type,
-	typeMap TypeMap,
-	exclude ExcludeFieldTag,
-) graphql.Fields {
+// Reflect the fields of the struct type t, which is named parent in graphql
+func (r *reflection) reflectFields(parent GqlName, t reflect.Type) graphql.Fields {
+	r.claimNames(t)
+	fields := make(graphql.Fields)
 	if t.Kind() != reflect.Struct {
-		panic(fmt.Sprintf(`ReflectFieldsFq can only work on struct types.
-			Received instead %s`, t.Kind()))
+		r.fail(t, `ReflectFieldsFq can only work on struct types.
+			Received instead %s`, t.Kind())
+		return fields
 	}
-	fields := make(graphql.Fields)
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		if includeField(f, exclude) {
-			name := GqlName(GetFieldFirstTag(f, "json"))
-			fields[string(name)] = ReflectFieldFq(name, f.Type, typeMap, exclude)
+	for _, f := range structFields(t, r.naming) {
+		if includeStructField(f, r.exclude) {
+			name := f.name
+			pop := r.at(f.goPath())
+			r.checkName(f, name)
+			fields[string(name)] = r.reflectStructField(parent, t, f)
+			pop()
 		}
 	}
+	r.reflectMethodFields(parent, t, fields)
 	return fields
 }
 
-// ReflectFieldFq returns a Graphql field that represents
-// the go reflect.Field (recorsively)
-func ReflectFieldFq(
+// Reflect the field f of the struct type t, which is named parent in graphql
+func (r *reflection) reflectStructField(
+	parent GqlName,
+	t reflect.Type,
+	f structField,
+) *graphql.Field {
+	var field *graphql.Field
+	intScalar := r.intFieldScalar(f)
+	switch {
+	case f.Tag.Get(GqlMapTagName) == GqlMapJSON:
+		field = &graphql.Field{
+			Name:    string(f.name),
+			Type:    JSON,
+			Resolve: trivialResolver,
+		}
+	case f.quoted:
+		field = &graphql.Field{
+			Name:    string(f.name),
+			Type:    graphql.String,
+			Resolve: quotedResolver,
+		}
+	case r.isTimeField(f):
+		field = r.reflectTimeField(f)
+	case intScalar != nil:
+		field = &graphql.Field{
+			Name:    string(f.name),
+			Type:    r.reflectIntType(f.Type, intScalar).(graphql.Output),
+			Resolve: intRangeResolver(f.Type, intScalar, r.getResolver(f.Type)),
+		}
+	default:
+		field = r.reflectField(f.name, f.Type, parent+"_"+f.name)
+	}
+	if f.omitEmpty && r.omitEmptyAsNull {
+		field.Resolve = omitEmptyResolver(field.Resolve)
+	}
+	field.Resolve = fieldResolver(t, f.Index, r.naming, field.Resolve)
+	field.Type = r.applyNullability(f, field.Type).(graphql.Output)
+	field.Resolve = emptyListResolver(f.Type, field.Type, field.Resolve)
+	describeField(f.StructField, field)
+	return field
+}
+
+// Reflect the struct type t into a graphql object.
+// The object is registered before its fields are reflected, and its fields are
+// given by a thunk, so that fields may refer back to the object itself.
+func (r *reflection) reflectObject(name GqlName, t reflect.Type) *graphql.Object {
+	if obj, exists := r.cache.objects[t]; exists {
+		return obj
+	}
+	name = r.typeName(t, name)
+	var fields graphql.Fields
+	var interfaces []*graphql.Interface
+	obj := graphql.NewObject(graphql.ObjectConfig{
+		Name:        string(name),
+		Description: typeDescription(t),
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return fields
+		}),
+		Interfaces: graphql.InterfacesThunk(func() []*graphql.Interface {
+			return interfaces
+		}),
+	})
+	r.cache.objects[t] = obj
+	r.added(t, func() { delete(r.cache.objects, t) })
+	fields = r.reflectFields(name, t)
+	interfaces = r.reflectImplementedInterfaces(name, t, fields)
+	r.check(len(fields) > 0, t, "object %s has no fields, "+
+		"since none of the fields of %s is exported, tagged and not excluded", name, t)
+	return obj
+}
+
+// Reflect a field of type t, named name. typeName is the name to use for t if
+// it has no name of its own.
+func (r *reflection) reflectField(
 	name GqlName,
 	t reflect.Type,
-	typeMap TypeMap,
-	exclude ExcludeFieldTag,
+	typeName GqlName,
 ) *graphql.Field {
-	gqlType := ReflectTypeFq(name, t, typeMap, exclude)
-	resolver := getResolver(t, typeMap)
+	gqlType := r.reflectType(typeName, t)
+	resolver := intRangeResolver(t, gqlType, r.getResolver(t))
 	return &graphql.Field{
 		Name:    string(name),
 		Type:    gqlType,
@@ -127,32 +337,20 @@ func ReflectFieldFq(
 	}
 }
 
-var gqlTypeNameOrder = 0
-
-func generateGqlOTypeName(name GqlName) string {
-	gqlTypeNameOrder++
-	return fmt.Sprintf("%s_%d", name, gqlTypeNameOrder)
-}
-
-// Whether to include this StructField in the gql schema
-// excludeTagName is the value of gqlexclude to search for exclusion list
-func includeField(f reflect.StructField, exclude ExcludeFieldTag) bool {
-	fieldName := GetFieldFirstTag(f, "json")
-	if fieldName == "" {
-		return false
-	}
+// Whether the gqlexclude tag of this StructField lists exclude
+func isExcluded(f reflect.StructField, exclude ExcludeFieldTag) bool {
 	gqlexclude := f.Tag.Get(GqlExcludeTagName)
 	if gqlexclude == "" {
 		// No exclusions
-		return true
+		return false
 	}
 	for _, s := range strings.Split(gqlexclude, ",") {
 		if strings.Trim(s, " ") == string(exclude) {
 			// excluded
-			return false
+			return true
 		}
 	}
-	return true
+	return false
 }
 
 // GetFieldFirstTag gets the StructField first tag value. Empty string if the tag
@@ -173,11 +371,32 @@ func getGqlType(t reflect.Type, typeMap TypeMap) graphql.Output {
 	return nil
 }
 
-func getResolver(t reflect.Type, typeMap TypeMap) graphql.FieldResolveFn {
-	m, exists := typeMap[t]
-	if !exists {
-		// By default use the trivial resolver
-		return trivialResolver
+// Get the resolver of values of type t
+func (o *options) getResolver(t reflect.Type) graphql.FieldResolveFn {
+	if m := o.marshalingOf(t); m != notMarshaled {
+		return convertingResolver(m.convert)
+	}
+	m, exists := o.typeMap[t]
+	if exists {
+		return typeMapResolver(m.Resolver)
+	}
+	if isValuer(t) {
+		return valuerResolver
+	}
+	if convert := o.getConverter(t); convert != nil {
+		return convertingResolver(convert)
+	}
+	if t.Kind() == reflect.Ptr {
+		return ptrResolver(o.getResolver(t.Elem()))
+	}
+	// By default use the trivial resolver
+	return trivialResolver
+}
+
+// Get the type t points to, if t is a pointer (or a pointer to a pointer...)
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	return m.Resolver
+	return t
 }
@@ -0,0 +1,126 @@
+package reflector
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ReflectArgs is a shorthand method for invoking ReflectArgsFq with the
+// default type map and no exclude tags at all.
+func ReflectArgs(instance interface{}, opts ...Option) graphql.FieldConfigArgument {
+	if instance == nil {
+		panic("Cannot infer type of nil instance")
+	}
+	return ReflectArgsFq(
+		reflect.TypeOf(instance),
+		GetDefaultTypeMap(),
+		ExcludeFieldTag(""),
+		opts...,
+	)
+}
+
+// ReflectArgsFq returns the graphql arguments reflected from the fields of the
+// struct type t (or a pointer to it), the same way input object fields are.
+// The gqldefault tag sets the default value of an argument, and the gqldesc
+// tag its description. Use DecodeArgs in order to decode the arguments back
+// into a t.
+func ReflectArgsFq(
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) graphql.FieldConfigArgument {
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectArgs(t)
+}
+
+func (r *reflection) reflectArgs(t reflect.Type) graphql.FieldConfigArgument {
+	t = indirectType(t)
+	r.claimNames(t)
+	args := make(graphql.FieldConfigArgument)
+	if t.Kind() != reflect.Struct {
+		r.fail(t, `ReflectArgsFq can only work on struct types.
+			Received instead %s`, t.Kind())
+		return args
+	}
+	parent := r.namer(t, GqlName(t.Name()))
+	for _, f := range structFields(t, r.naming) {
+		if includeStructField(f, r.exclude) {
+			name := f.name
+			pop := r.at(f.goPath())
+			r.checkName(f, name)
+			gqlType := r.reflectInputFieldType(parent, f)
+			defaultValue, err := getDefaultValue(f.StructField)
+			if err != nil {
+				r.fail(f.Type, "%s", err)
+			}
+			args[string(name)] = &graphql.ArgumentConfig{
+				Type:         r.applyNullability(f, gqlType),
+				DefaultValue: defaultValue,
+				Description:  f.Tag.Get(GqlDescTagName),
+			}
+			pop()
+		}
+	}
+	return args
+}
+
+// Get the default value of the argument reflected from the struct field f,
+// as set by its gqldefault tag. Strings are taken as is, integers are parsed
+// by the size of their type, and any other value is parsed as JSON. Arrays
+// need exactly as many values as their length.
+func getDefaultValue(f reflect.StructField) (interface{}, error) {
+	tag, exists := f.Tag.Lookup(GqlDefaultTagName)
+	if !exists {
+		return nil, nil
+	}
+	t := indirectType(f.Type)
+	if t == timeType || t == durationType || t == urlType {
+		// Parsed by their scalars
+		return tag, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return tag, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(tag, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s default value %q of %s: %s",
+				t, tag, f.Name, err)
+		}
+		if int64(int(i)) != i {
+			// Encoded as Int64 values are
+			return strconv.FormatInt(i, 10), nil
+		}
+		return int(i), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u, err := strconv.ParseUint(tag, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s default value %q of %s: %s",
+				t, tag, f.Name, err)
+		}
+		if i := int(u); i < 0 || uint64(i) != u {
+			// Encoded as UInt64 values are
+			return strconv.FormatUint(u, 10), nil
+		}
+		return int(u), nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(tag), &value); err != nil {
+		return nil, fmt.Errorf("Invalid %s default value %q of %s: %s",
+			t, tag, f.Name, err)
+	}
+	if list, isList := value.([]interface{}); isList && t.Kind() == reflect.Array &&
+		len(list) != t.Len() {
+		// Decoding it would leave the rest of the array as is
+		return nil, fmt.Errorf("Invalid %s default value %q of %s: %d values instead of %d",
+			t, tag, f.Name, len(list), t.Len())
+	}
+	return value, nil
+}
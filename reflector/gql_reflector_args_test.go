@@ -0,0 +1,172 @@
+package reflector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type argsKind string
+
+type subArgs struct {
+	X int      `json:"x"`
+	Y []string `json:"y"`
+}
+
+type testArgs struct {
+	Name    string         `json:"name" gqlnull:"required" gqldesc:"The name"`
+	Limit   int            `json:"limit" gqldefault:"10"`
+	Ratio   *float64       `json:"ratio"`
+	Kind    argsKind       `json:"kind" gqldefault:"default_kind"`
+	Tags    []string       `json:"tags" gqldefault:"[\"a\", \"b\"]"`
+	Sub     *subArgs       `json:"sub"`
+	Counts  map[string]int `json:"counts"`
+	Since   time.Time      `json:"since"`
+	Small   int8           `json:"small"`
+	Ignored string         `json:"ignored" gqlexclude:"args"`
+	NoJSON  string
+}
+
+func TestReflectArgs(t *testing.T) {
+	as := assert.New(t)
+	args := ReflectArgsFq(reflect.TypeOf(testArgs{}), GetDefaultTypeMap(), ExcludeFieldTag("args"))
+
+	types := make(map[string]string)
+	for name, arg := range args {
+		types[name] = arg.Type.String()
+	}
+	as.Equal(map[string]string{
+		"name":   "String!",
+		"limit":  "Int",
+		"ratio":  "Float",
+		"kind":   "String",
+		"tags":   "[String]",
+		"sub":    "subArgsInput",
+		"counts": "[testArgs_counts_entryInput]",
+		"since":  "DateTime",
+		"small":  "Int",
+	}, types)
+	as.Equal("The name", args["name"].Description)
+	as.Equal(10, args["limit"].DefaultValue)
+	as.Equal("default_kind", args["kind"].DefaultValue)
+	as.Equal([]interface{}{"a", "b"}, args["tags"].DefaultValue)
+	as.Nil(args["ratio"].DefaultValue)
+}
+
+func TestDefaultValues(t *testing.T) {
+	as := assert.New(t)
+	type S struct {
+		Small   uint8      `gqldefault:"200"`
+		Big     uint64     `gqldefault:"18446744073709551615"`
+		Over    uint8      `gqldefault:"300"`
+		Minus   uint       `gqldefault:"-1"`
+		Signed  int8       `gqldefault:"-128"`
+		Pair    [2]int     `gqldefault:"[1, 2]"`
+		Short   [2]int     `gqldefault:"[1]"`
+		Pointer *[2]string `gqldefault:"[\"a\", \"b\", \"c\"]"`
+	}
+	defaultValue := func(name string) (interface{}, error) {
+		f, _ := reflect.TypeOf(S{}).FieldByName(name)
+		return getDefaultValue(f)
+	}
+
+	value, err := defaultValue("Small")
+	as.Nil(err)
+	as.Equal(200, value)
+	value, err = defaultValue("Big")
+	as.Nil(err)
+	as.Equal("18446744073709551615", value)
+	value, err = defaultValue("Signed")
+	as.Nil(err)
+	as.Equal(-128, value)
+	value, err = defaultValue("Pair")
+	as.Nil(err)
+	as.Equal([]interface{}{float64(1), float64(2)}, value)
+
+	_, err = defaultValue("Over")
+	as.EqualError(err, `Invalid uint8 default value "300" of Over: `+
+		`strconv.ParseUint: parsing "300": value out of range`)
+	_, err = defaultValue("Minus")
+	as.EqualError(err, `Invalid uint default value "-1" of Minus: `+
+		`strconv.ParseUint: parsing "-1": invalid syntax`)
+	_, err = defaultValue("Short")
+	as.EqualError(err, `Invalid [2]int default value "[1]" of Short: 1 values instead of 2`)
+	_, err = defaultValue("Pointer")
+	as.EqualError(err, `Invalid [2]string default value "[\"a\", \"b\", \"c\"]" of Pointer: `+
+		`3 values instead of 2`)
+}
+
+func TestDecodeArgs(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+
+	var decoded testArgs
+	f := graphql.Field{
+		Type: graphql.String,
+		Args: ReflectArgs(testArgs{}),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			decoded = testArgs{}
+			if err := DecodeArgs(p, &decoded); err != nil {
+				return nil, err
+			}
+			return decoded.Name, nil
+		},
+	}
+	assertQuery(t, f, "s", `(
+		name: "n"
+		ratio: 0.5
+		sub: {x: 3, y: ["y"]}
+		counts: [{key: "a", value: 1}]
+		since: "2009-11-10T23:00:00Z"
+	)`, `{"data":{"s":"n"}}`, "")
+	req.NotNil(decoded.Ratio)
+	as.Equal(0.5, *decoded.Ratio)
+	decoded.Ratio = nil
+	as.Equal(testArgs{
+		Name:   "n",
+		Limit:  10,
+		Kind:   "default_kind",
+		Tags:   []string{"a", "b"},
+		Sub:    &subArgs{X: 3, Y: []string{"y"}},
+		Counts: map[string]int{"a": 1},
+		Since:  time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC),
+	}, decoded)
+
+	assertQuery(t, f, "s", `(name: "n", small: 1000)`, "",
+		`cannot decode argument "small": 1000 overflows int8`)
+}
+
+func TestDecodeInput(t *testing.T) {
+	as := assert.New(t)
+
+	var i int
+	as.EqualError(DecodeInput("x", &i), `cannot decode argument "": cannot decode string into int`)
+	as.Nil(DecodeInput(float64(5), &i))
+	as.Equal(5, i)
+	as.EqualError(DecodeInput(5.5, &i), `cannot decode argument "": cannot decode float64 into int`)
+
+	var u uint
+	as.EqualError(DecodeInput(-1, &u), `cannot decode argument "": -1 overflows uint`)
+
+	var sub subArgs
+	as.EqualError(DecodeInput(map[string]interface{}{
+		"x": 1,
+		"y": []interface{}{"a", 2},
+	}, &sub), `cannot decode argument "y[1]": cannot decode int into string`)
+
+	pair := [2]int{5, 6}
+	as.EqualError(DecodeInput([]interface{}{1}, &pair),
+		`cannot decode argument "": cannot decode 1 values into [2]int`)
+	as.Nil(DecodeInput([]interface{}{1, 2}, &pair))
+	as.Equal([2]int{1, 2}, pair)
+
+	var m map[string]int
+	as.Nil(DecodeInput(map[string]interface{}{"a": 1}, &m))
+	as.Equal(map[string]int{"a": 1}, m)
+
+	as.NotNil(DecodeArgs(graphql.ResolveParams{}, sub))
+}
@@ -0,0 +1,85 @@
+package reflector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type benchItem struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Quantity    int     `json:"quantity"`
+	Available   bool    `json:"available"`
+	Category    string  `json:"category"`
+	Vendor      string  `json:"vendor"`
+}
+
+func benchItems() []benchItem {
+	items := make([]benchItem, 1000)
+	for i := range items {
+		items[i] = benchItem{ID: i, Name: "item", Price: 1.5, Vendor: "vendor"}
+	}
+	return items
+}
+
+// Resolve the vendor field, the last one, with the given resolver
+func benchmarkFieldResolve(b *testing.B, resolve graphql.FieldResolveFn) {
+	items := benchItems()
+	p := graphql.ResolveParams{Info: graphql.ResolveInfo{FieldName: "vendor"}}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Source = items[i%len(items)]
+		if _, err := resolve(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFieldResolveByIndex(b *testing.B) {
+	fields := ReflectFieldsFq(reflect.TypeOf(benchItem{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	benchmarkFieldResolve(b, fields["vendor"].Resolve)
+}
+
+// The way fields used to be resolved, scanning the fields for the name
+func BenchmarkFieldResolveByScan(b *testing.B) {
+	benchmarkFieldResolve(b, func(p graphql.ResolveParams) (interface{}, error) {
+		v := indirectValue(reflect.ValueOf(p.Source))
+		for i := 0; i < v.NumField(); i++ {
+			if GetFieldFirstTag(v.Type().Field(i), "json") == p.Info.FieldName {
+				return v.Field(i).Interface(), nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+func BenchmarkListQuery(b *testing.B) {
+	items := benchItems()
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{
+				Type: graphql.NewList(ReflectType(benchItem{})),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return items, nil
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		b.Fatal(err)
+	}
+	request := "{items {id name description price quantity available category vendor}}"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := graphql.Do(graphql.Params{Schema: schema, RequestString: request})
+		if len(r.Errors) > 0 {
+			b.Fatal(r.Errors)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package reflector
 
 import (
+	"context"
+	"encoding/json"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/graphql-go/graphql"
@@ -10,39 +13,240 @@ import (
 var defaultTypeMap TypeMap
 
 func init() {
-	defaultTypeMap = buildDefaultTypeMap()
+	defaultTypeMap = buildDefaultTypeMap().Override(sqlNullTypeMap(), bigTypeMap())
+	for _, m := range defaultTypeMap {
+		if m.Resolver != nil {
+			ownResolvers = append(ownResolvers, m.Resolver)
+		}
+	}
 }
 
 // GetValueFromResolveParams gets the value of p, translating a graphql construct
-// to a golang `reflect.Value`
+// to a golang `reflect.Value`.
+// The source of p may be a struct, with fields named by their json tags, or a
+// map keyed by graphql field names, and it may be held by pointers and
+// interfaces. The value is invalid if the source is nil or has no such field.
+// Pointer and interface fields are dereferenced, unless they are nil.
+// Resolvers of type map entries are given the struct of their field as
+// p.Source, and get the value of the field by it, which also works for fields
+// that are not held by a struct, such as the results of functions.
 func GetValueFromResolveParams(p graphql.ResolveParams) reflect.Value {
-	reflected := reflect.ValueOf(p.Source)
-	fieldName := p.Info.FieldName
-	value := findFieldByTag(reflect.Indirect(reflected), "json", GqlName(fieldName))
-	return value
+	return getValue(p, defaultFieldNaming)
+}
+
+// Like GetValueFromResolveParams, with struct fields named by naming
+func getValue(p graphql.ResolveParams, naming fieldNaming) reflect.Value {
+	if source, ok := p.Source.(valueSource); ok {
+		return indirectValue(source.value)
+	}
+	if p.Context != nil {
+		// The value given to a type map resolver (see typeMapResolver)
+		if value, ok := p.Context.Value(fieldValueKey{}).(reflect.Value); ok {
+			return indirectValue(value)
+		}
+	}
+	source := indirectValue(reflect.ValueOf(p.Source))
+	fieldName := GqlName(p.Info.FieldName)
+	switch source.Kind() {
+	case reflect.Struct:
+		return indirectValue(findField(source, naming, fieldName))
+	case reflect.Map:
+		if source.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		key := reflect.ValueOf(fieldName).Convert(source.Type().Key())
+		return indirectValue(source.MapIndex(key))
+	}
+	return reflect.Value{}
+}
+
+// valueSource is the source of resolve params that resolve the value itself,
+// rather than a field of the source, for example the result of a function.
+// It's used for applying the resolver of a type to a value of that type.
+type valueSource struct {
+	value reflect.Value
+	// The source that holds the value, such as its struct
+	parent interface{}
+}
+
+// fieldValueKey is the context key of the value resolved by a type map
+// resolver
+type fieldValueKey struct{}
+
+// The resolvers of the default type map, which resolve the values of
+// valueSources as they are
+var ownResolvers []graphql.FieldResolveFn
+
+// Wrap resolve, the resolver of a type map entry, so that it's given the
+// source that holds the value as p.Source, such as the struct of a field,
+// rather than a valueSource, since it may look into p.Source. The value
+// itself is given by the context, for GetValueFromResolveParams. The
+// resolvers of the default type map are given the valueSource as is.
+func typeMapResolver(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if resolve == nil {
+		return nil
+	}
+	for _, own := range ownResolvers {
+		if sameResolver(resolve, own) {
+			return resolve
+		}
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if source, ok := p.Source.(valueSource); ok {
+			ctx := p.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			p.Context = context.WithValue(ctx, fieldValueKey{}, source.value)
+			p.Source = source.parent
+		}
+		return resolve(p)
+	}
+}
+
+// Dereference v as long as it is a non nil pointer or interface
+func indirectValue(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
 }
 
 func trivialResolver(p graphql.ResolveParams) (interface{}, error) {
 	value := GetValueFromResolveParams(p)
+	if !value.IsValid() {
+		return nil, nil
+	}
 	return value.Interface(), nil
 }
 
-func findFieldByTag(v reflect.Value, tagName string, fieldName GqlName) reflect.Value {
-	t := v.Type()
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		tag := GetFieldFirstTag(f, tagName)
-		if tag == string(fieldName) {
-			return v.Field(i)
+// Wrap resolver, the resolver of the field at the index sequence index of the
+// struct type t, with getting the field by its index, rather than looking it up
+// by its name on every resolve. The fields of sources that are not a t are
+// looked up by their names, as given by naming.
+// Fields that are missing from the source, such as fields promoted through nil
+// embedded pointers, resolve to null.
+func fieldResolver(
+	t reflect.Type,
+	index []int,
+	naming fieldNaming,
+	resolver graphql.FieldResolveFn,
+) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		var value reflect.Value
+		source := indirectValue(reflect.ValueOf(p.Source))
+		if source.IsValid() && source.Type() == t {
+			value = fieldByIndex(source, index)
+		} else {
+			// Some other source, such as a map
+			value = getValue(p, naming)
+		}
+		if !value.IsValid() {
+			return nil, nil
 		}
+		p.Source = valueSource{value: value, parent: p.Source}
+		return resolver(p)
+	}
+}
+
+// Resolves fields whose tag has the string option to their value encoded as
+// JSON, the same as encoding/json encodes them
+func quotedResolver(p graphql.ResolveParams) (interface{}, error) {
+	value := GetValueFromResolveParams(p)
+	if !value.IsValid() || value.Kind() == reflect.Ptr {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Wrap resolver with resolving empty values to null, the same as encoding/json
+// omits them from fields whose tag has the omitempty option
+func omitEmptyResolver(resolver graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if source, ok := p.Source.(valueSource); ok && isEmptyValue(source.value) {
+			return nil, nil
+		}
+		return resolver(p)
 	}
-	return reflect.Value{}
+}
+
+// Whether v is empty, as encoding/json sees it
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Resolves nil pointers to null and delegates everything else to resolver
+func ptrResolver(resolver graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		value := GetValueFromResolveParams(p)
+		if value.Kind() == reflect.Ptr {
+			// Only nil pointers are not dereferenced
+			return nil, nil
+		}
+		return resolver(p)
+	}
+}
+
+// fieldIndexKey is the key of the field indexes of a struct type by the
+// names of the fields
+type fieldIndexKey struct {
+	t      reflect.Type
+	naming fieldNaming
+}
+
+// The index sequences of the fields of struct types by their names, a
+// map[GqlName][]int by fieldIndexKey, so that the fields of every struct type
+// are only looked into once
+var fieldIndexes sync.Map
+
+// Get the field of the struct value v named fieldName by naming, which may be
+// promoted from an embedded struct (see structFields)
+func findField(v reflect.Value, naming fieldNaming, fieldName GqlName) reflect.Value {
+	key := fieldIndexKey{t: v.Type(), naming: naming}
+	indexes, exists := fieldIndexes.Load(key)
+	if !exists {
+		byName := make(map[GqlName][]int)
+		for _, f := range structFields(v.Type(), naming) {
+			byName[f.name] = f.Index
+		}
+		indexes, _ = fieldIndexes.LoadOrStore(key, byName)
+	}
+	index, exists := indexes.(map[GqlName][]int)[fieldName]
+	if !exists {
+		return reflect.Value{}
+	}
+	return fieldByIndex(v, index)
 }
 
 // Format time as string in RFC3339
 func timeResolver(p graphql.ResolveParams) (interface{}, error) {
 	value := GetValueFromResolveParams(p)
-	t := value.Interface().(time.Time)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	t, ok := value.Interface().(time.Time)
+	if !ok {
+		// Such as a string held by a map source
+		return value.Interface(), nil
+	}
 	return t.UTC().Format(time.RFC3339), nil
 }
 
@@ -121,13 +325,22 @@ func buildDefaultTypeMap() TypeMap {
 			Resolver: trivialResolver,
 		},
 		reflect.TypeOf(time.Now()): {
-			Output:   graphql.String,
+			Output:   DateTime,
 			Resolver: timeResolver,
 		},
+		reflect.TypeOf(time.Duration(0)): {
+			Output:   Duration,
+			Resolver: trivialResolver,
+		},
+		urlType: {
+			Output:   graphql.String,
+			Resolver: urlResolver,
+		},
 	}
 }
 
-// GetDefaultTypeMap returns a default type map, including all the native types
+// GetDefaultTypeMap returns a default type map, including all the native types.
+// Every call returns a new copy, which the caller may change.
 func GetDefaultTypeMap() TypeMap {
-	return defaultTypeMap
+	return defaultTypeMap.Clone()
 }
@@ -0,0 +1,138 @@
+package reflector
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+)
+
+// TypeCache holds the graphql types reflected from go types.
+// Types that are reflected separately, but end up in the same graphql schema,
+// must share a TypeCache (see WithTypeCache), since a schema may not contain
+// two different types by the same name. The Reflect* functions share a default
+// cache among the reflections that use the same type map and options, unless
+// they're given a cache, a TypeNamer or interfaces and unions to reflect.
+// Type maps are the same if they map the same go types to identical entries,
+// whose resolvers are the same function values rather than closures of the
+// same function (see TypeMap.Merge).
+// A TypeCache is safe for concurrent use, but it should only be shared by
+// reflections that use the same type map and options.
+type TypeCache struct {
+	mu sync.Mutex
+	// The graphql objects reflected so far, so every go type is reflected
+	// only once, and recursive types refer back to their own object
+	objects map[reflect.Type]*graphql.Object
+	// The same for graphql input objects
+	inputs map[reflect.Type]*graphql.InputObject
+	// The graphql enums reflected from go types that have a Values method
+	enums map[reflect.Type]*graphql.Enum
+	// The graphql interfaces and unions reflected from go interface types
+	abstracts map[reflect.Type]graphql.Output
+	// The go type of every graphql type name given so far
+	names map[GqlName]reflect.Type
+	// Whether the cache is a default cache of the Reflect* functions
+	isDefault bool
+}
+
+// NewTypeCache returns a new empty TypeCache
+func NewTypeCache() *TypeCache {
+	return &TypeCache{
+		objects:   make(map[reflect.Type]*graphql.Object),
+		inputs:    make(map[reflect.Type]*graphql.InputObject),
+		enums:     make(map[reflect.Type]*graphql.Enum),
+		abstracts: make(map[reflect.Type]graphql.Output),
+		names:     make(map[GqlName]reflect.Type),
+	}
+}
+
+// Types returns the graphql types reflected so far, ordered by name.
+// Add them to the types of a schema, so the schema knows the objects that
+// implement reflected interfaces even if they're not reachable otherwise.
+func (c *TypeCache) Types() []graphql.Type {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.types(nil)
+}
+
+// Get the graphql types reflected from the go types in reached, or from any
+// go type if reached is nil, ordered by name
+func (c *TypeCache) types(reached map[reflect.Type]bool) []graphql.Type {
+	var types []graphql.Type
+	include := func(t reflect.Type, gqlType graphql.Type) {
+		if reached == nil || reached[t] {
+			types = append(types, gqlType)
+		}
+	}
+	for t, obj := range c.objects {
+		include(t, obj)
+	}
+	for t, input := range c.inputs {
+		include(t, input)
+	}
+	for t, enum := range c.enums {
+		include(t, enum)
+	}
+	for t, abstract := range c.abstracts {
+		include(t, abstract)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name() < types[j].Name()
+	})
+	return types
+}
+
+// The default caches of the Reflect* functions, one for each configuration
+// they were called with
+var defaultCaches struct {
+	mu     sync.Mutex
+	caches []configuredCache
+}
+
+// configuredCache is a default cache, along with the options of the
+// reflections that share it
+type configuredCache struct {
+	options
+	cache *TypeCache
+}
+
+// Get the default cache of the reflections configured by o, nil if they may
+// not share one, since they name types or reflect interfaces by functions and
+// registrations that can't be told apart
+func defaultCache(o options) *TypeCache {
+	if reflect.ValueOf(o.namer).Pointer() != reflect.ValueOf(GoTypeNamer).Pointer() ||
+		len(o.polymorphic) > 0 {
+		return nil
+	}
+	defaultCaches.mu.Lock()
+	defer defaultCaches.mu.Unlock()
+	for _, c := range defaultCaches.caches {
+		if c.sameConfiguration(o) {
+			return c.cache
+		}
+	}
+	cache := NewTypeCache()
+	cache.isDefault = true
+	o.typeMap = o.typeMap.Clone()
+	defaultCaches.caches = append(defaultCaches.caches, configuredCache{o, cache})
+	return cache
+}
+
+// Whether reflections configured by o reflect go types the same as those of
+// the cache
+func (c configuredCache) sameConfiguration(o options) bool {
+	if c.exclude != o.exclude || c.nullability != o.nullability ||
+		c.inputSuffix != o.inputSuffix || c.naming != o.naming ||
+		c.omitEmptyAsNull != o.omitEmptyAsNull || c.zeroTimeAsNull != o.zeroTimeAsNull ||
+		c.timeFormatArg != o.timeFormatArg || c.marshalers != o.marshalers ||
+		c.strictIntegers != o.strictIntegers || len(c.typeMap) != len(o.typeMap) {
+		return false
+	}
+	for t, mapping := range o.typeMap {
+		if m, exists := c.typeMap[t]; !exists || !sameMapping(m, mapping) {
+			return false
+		}
+	}
+	return true
+}
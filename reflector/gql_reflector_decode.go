@@ -0,0 +1,347 @@
+package reflector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// DecodeArgs decodes the arguments of the field resolved by p into dst, which
+// must be a pointer to a struct, typically the one the arguments were
+// reflected from by ReflectArgsFq.
+// Arguments that are missing or null leave the matching struct fields as they
+// are. Pass the options that name fields (see WithTagKey) the same as they
+// were passed to ReflectArgsFq.
+func DecodeArgs(p graphql.ResolveParams, dst interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	return decodeArgs(p, dst, decoder{&o})
+}
+
+func decodeArgs(p graphql.ResolveParams, dst interface{}, d decoder) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode arguments into %T, a struct pointer is needed", dst)
+	}
+	return d.decodeFields("", p.Args, v.Elem())
+}
+
+// DecodeInput decodes the value of an argument or an input object field, as
+// given by graphql, into dst, which must be a pointer. Input objects are
+// decoded into structs the same way DecodeArgs decodes arguments.
+func DecodeInput(value interface{}, dst interface{}, opts ...Option) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("cannot decode input into %T, a non nil pointer is needed", dst)
+	}
+	o := newOptions(opts)
+	return decoder{&o}.decodeValue("", value, v.Elem())
+}
+
+// decoder decodes graphql input values into go values
+type decoder struct {
+	// The options the go types were reflected by, such as the way the fields
+	// of structs are named
+	*options
+}
+
+// Decode the input object values into the fields of the struct dst.
+// path is the path of dst within the arguments.
+func (d decoder) decodeFields(path string, values map[string]interface{}, dst reflect.Value) error {
+	for _, f := range structFields(dst.Type(), d.naming) {
+		name := string(f.name)
+		value, exists := values[name]
+		if !exists || value == nil {
+			continue
+		}
+		field, err := allocFieldByIndex(dst, f.Index)
+		if err != nil {
+			return fmt.Errorf("cannot decode argument %q: %s", joinPath(path, name), err)
+		}
+		if _, tagged := f.Tag.Lookup(GqlTimeTagName); tagged && indirectType(f.Type) == timeType {
+			var format timeFormat
+			if format, err = getTimeFormat(f.StructField); err == nil {
+				err = d.decodeTime(joinPath(path, name), value, field, format)
+			}
+		} else if f.quoted {
+			err = decodeQuoted(joinPath(path, name), value, field)
+		} else {
+			err = d.decodeValue(joinPath(path, name), value, field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode the graphql input value src into dst.
+// path is the path of dst within the arguments, for error messages.
+func (d decoder) decodeValue(path string, src interface{}, dst reflect.Value) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Type().AssignableTo(dst.Type()) {
+		dst.Set(srcValue)
+		return nil
+	}
+	mismatch := func() error {
+		return fmt.Errorf("cannot decode argument %q: cannot decode %T into %s",
+			path, src, dst.Type())
+	}
+	if m := d.unmarshalingOf(dst.Type()); m != notMarshaled && dst.CanAddr() {
+		return m.decode(path, src, dst)
+	}
+	if dst.CanAddr() && d.scans(dst.Type()) {
+		return decodeScanner(path, src, dst)
+	}
+	if scalar, ok := bigScalars[dst.Type()]; ok {
+		parsed := reflect.ValueOf(scalar.ParseValue(src))
+		if !parsed.IsValid() {
+			return mismatch()
+		}
+		dst.Set(parsed.Elem())
+		return nil
+	}
+	if s, ok := src.(string); ok && dst.Type() == urlType {
+		u, err := url.Parse(s)
+		if err != nil {
+			return fmt.Errorf("cannot decode argument %q: %s", path, err)
+		}
+		dst.Set(reflect.ValueOf(*u))
+		return nil
+	}
+	if s, ok := src.(string); ok && dst.Type() == durationType {
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("cannot decode argument %q: %s", path, err)
+		}
+		dst.SetInt(int64(duration))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := d.decodeValue(path, src, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.String:
+		if srcValue.Kind() != reflect.String {
+			return mismatch()
+		}
+		dst.SetString(srcValue.String())
+		return nil
+	case reflect.Bool:
+		if srcValue.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		dst.SetBool(srcValue.Bool())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(srcValue)
+		if s, isString := src.(string); isString {
+			// Such as the value of an Int64
+			var err error
+			i, err = strconv.ParseInt(s, 10, 64)
+			ok = err == nil
+		}
+		if !ok {
+			return mismatch()
+		}
+		if dst.OverflowInt(i) {
+			return fmt.Errorf("cannot decode argument %q: %d overflows %s",
+				path, i, dst.Type())
+		}
+		dst.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u, ok := toUint64(srcValue)
+		if s, isString := src.(string); isString {
+			// Such as the value of a UInt64
+			var err error
+			u, err = strconv.ParseUint(s, 10, 64)
+			ok = err == nil
+		}
+		if i, isInt := toInt64(srcValue); isInt && i < 0 {
+			return fmt.Errorf("cannot decode argument %q: %d overflows %s",
+				path, i, dst.Type())
+		}
+		if !ok {
+			return mismatch()
+		}
+		if dst.OverflowUint(u) {
+			return fmt.Errorf("cannot decode argument %q: %d overflows %s",
+				path, u, dst.Type())
+		}
+		dst.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch srcValue.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = srcValue.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(srcValue.Int())
+		default:
+			return mismatch()
+		}
+		if dst.OverflowFloat(f) {
+			return fmt.Errorf("cannot decode argument %q: %v overflows %s",
+				path, f, dst.Type())
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.Slice, reflect.Array:
+		if srcValue.Kind() != reflect.Slice {
+			return mismatch()
+		}
+		n := srcValue.Len()
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+		} else if n != dst.Len() {
+			// Fewer values would leave the rest of the array as is
+			return fmt.Errorf("cannot decode argument %q: cannot decode %d values into %s",
+				path, n, dst.Type())
+		}
+		for i := 0; i < n; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if err := d.decodeValue(elemPath, srcValue.Index(i).Interface(), dst.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		return d.decodeMap(path, srcValue, dst)
+	case reflect.Struct:
+		if dst.Type() == timeType {
+			s, ok := src.(string)
+			if !ok {
+				return mismatch()
+			}
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return fmt.Errorf("cannot decode argument %q: %s", path, err)
+			}
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+		values, ok := src.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		return d.decodeFields(path, values, dst)
+	}
+	return mismatch()
+}
+
+// Decode the value of a field whose tag has the string option, which is given
+// encoded as JSON, into dst
+func decodeQuoted(path string, src interface{}, dst reflect.Value) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("cannot decode argument %q: cannot decode %T into %s",
+			path, src, dst.Type())
+	}
+	if err := json.Unmarshal([]byte(s), dst.Addr().Interface()); err != nil {
+		return fmt.Errorf("cannot decode argument %q: %s", path, err)
+	}
+	return nil
+}
+
+// Decode a map, given either as a JSON object or as a list of entries
+func (d decoder) decodeMap(path string, src reflect.Value, dst reflect.Value) error {
+	t := dst.Type()
+	m := reflect.MakeMap(t)
+	decodeEntry := func(key interface{}, value interface{}) error {
+		k := reflect.New(t.Key()).Elem()
+		if err := d.decodeValue(path, key, k); err != nil {
+			return err
+		}
+		v := reflect.New(t.Elem()).Elem()
+		if err := d.decodeValue(fmt.Sprintf("%s[%v]", path, key), value, v); err != nil {
+			return err
+		}
+		m.SetMapIndex(k, v)
+		return nil
+	}
+
+	switch src.Kind() {
+	case reflect.Map:
+		for _, key := range src.MapKeys() {
+			if err := decodeEntry(key.Interface(), src.MapIndex(key).Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < src.Len(); i++ {
+			entry, ok := src.Index(i).Interface().(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("cannot decode argument %q: cannot decode %T into a %s entry",
+					path, src.Index(i).Interface(), t)
+			}
+			if err := decodeEntry(entry["key"], entry["value"]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot decode argument %q: cannot decode %s into %s",
+			path, src.Type(), t)
+	}
+	dst.Set(m)
+	return nil
+}
+
+// Get the integer value of v, which may also be a float with no fraction, as
+// JSON numbers are
+func toInt64(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// Get the unsigned integer value of v, which may also be a non negative signed
+// integer or a float with no fraction
+func toUint64(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	}
+	i, ok := toInt64(v)
+	if !ok || i < 0 {
+		return 0, false
+	}
+	return uint64(i), true
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+var timeType = reflect.TypeOf(time.Time{})
@@ -0,0 +1,36 @@
+package reflector
+
+import (
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Describer is implemented by go types that describe the graphql types
+// reflected from them (objects, input objects and enums).
+// GqlDescription is called on the zero value of the type. For example
+//
+//	func (User) GqlDescription() string {
+//		return "A registered user"
+//	}
+type Describer interface {
+	GqlDescription() string
+}
+
+var describerType = reflect.TypeOf((*Describer)(nil)).Elem()
+
+// Get the description of the graphql type reflected from the go type t, as
+// given by its GqlDescription method, if it has one
+func typeDescription(t reflect.Type) string {
+	if t.Kind() == reflect.Interface || !reflect.PtrTo(t).Implements(describerType) {
+		return ""
+	}
+	return reflect.New(t).Interface().(Describer).GqlDescription()
+}
+
+// Set the description and deprecation reason of the field reflected from
+// the struct field f, as given by its gqldesc and gqldeprecated tags
+func describeField(f reflect.StructField, field *graphql.Field) {
+	field.Description = f.Tag.Get(GqlDescTagName)
+	field.DeprecationReason = f.Tag.Get(GqlDeprecatedTagName)
+}
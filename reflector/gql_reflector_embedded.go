@@ -0,0 +1,221 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// structField is a field of a struct type, which may be promoted from a
+// struct embedded in it
+type structField struct {
+	reflect.StructField
+	// The name of the field in graphql
+	name GqlName
+	// Whether the field is named by its tag, rather than by its go name
+	tagged bool
+	// Whether the tag of the field has the omitempty option
+	omitEmpty bool
+	// Whether the field is a string, number or bool whose tag has the string
+	// option, which encodes it as a JSON string
+	quoted bool
+	// The embedded struct fields the field is promoted through, outermost
+	// first, so that excluding one of them excludes the field as well
+	embedded []reflect.StructField
+	// Whether the field is promoted through an embedded pointer, in which
+	// case it's missing whenever that pointer is nil
+	viaPointer bool
+}
+
+// Get the fields of the struct type t that are named by naming, including the
+// fields promoted from embedded structs the way encoding/json promotes them:
+// embedded structs that have no name in their tag are flattened into t, a
+// shallower field shadows deeper fields of the same name, and fields of the
+// same name at the same depth shadow each other, unless only one of them is
+// tagged.
+// The Index of each field is the index sequence of the field within t, and
+// fields are ordered by it.
+func structFields(t reflect.Type, naming fieldNaming) []structField {
+	var fields []structField
+	// The embedded structs to look into at the current and the next depth
+	var current []structField
+	next := []structField{{StructField: reflect.StructField{Type: t}}}
+	// The struct types looked into at shallower depths
+	visited := make(map[reflect.Type]bool)
+	// The names of the fields at shallower depths
+	shallower := make(map[GqlName]bool)
+
+	for depth := 1; len(next) > 0; depth++ {
+		current, next = next, nil
+		// The number of fields, and tagged fields, of each name at this depth
+		count := make(map[GqlName]int)
+		taggedCount := make(map[GqlName]int)
+		seen := make(map[reflect.Type]bool)
+
+		for _, e := range current {
+			st := indirectType(e.Type)
+			if visited[st] {
+				continue
+			}
+			// A struct embedded twice at the same depth is looked into
+			// twice, so its fields shadow each other
+			seen[st] = true
+
+			for i := 0; i < st.NumField(); i++ {
+				f := st.Field(i)
+				ft := indirectType(f.Type)
+				if f.Anonymous {
+					if f.PkgPath != "" && ft.Kind() != reflect.Struct {
+						// unexported embedded non struct
+						continue
+					}
+				} else if f.PkgPath != "" {
+					// unexported
+					continue
+				}
+				if f.Tag.Get(naming.tagKey) == "-" {
+					// Unlike "-," which names the field -
+					continue
+				}
+				tag := GetFieldFirstTag(f, naming.tagKey)
+				index := make([]int, len(e.Index)+1)
+				copy(index, e.Index)
+				index[len(e.Index)] = i
+				f.Index = index
+
+				if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+					embedded := append(append([]reflect.StructField{}, e.embedded...), f)
+					next = append(next, structField{
+						StructField: f,
+						embedded:    embedded,
+						viaPointer:  e.viaPointer || f.Type.Kind() == reflect.Ptr,
+					})
+					continue
+				}
+				if tag == "" && !naming.goNames {
+					continue
+				}
+				name := naming.casing.apply(f.Name)
+				if tag != "" {
+					name = naming.casing.apply(tag)
+					taggedCount[name]++
+				}
+				if shallower[name] {
+					continue
+				}
+				count[name]++
+				fields = append(fields, structField{
+					StructField: f,
+					name:        name,
+					tagged:      tag != "",
+					omitEmpty:   hasTagOption(f, naming.tagKey, "omitempty"),
+					quoted:      hasTagOption(f, naming.tagKey, "string") && isQuotable(f.Type),
+					embedded:    e.embedded,
+					viaPointer:  e.viaPointer,
+				})
+			}
+		}
+
+		// Drop the fields of this depth that shadow each other
+		kept := fields[:0]
+		for _, f := range fields {
+			if len(f.Index) != depth || count[f.name] == 1 ||
+				(f.tagged && taggedCount[f.name] == 1) {
+				kept = append(kept, f)
+			}
+		}
+		fields = kept
+		for name := range count {
+			shallower[name] = true
+		}
+		for st := range seen {
+			visited[st] = true
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return lessIndex(fields[i].Index, fields[j].Index)
+	})
+	return fields
+}
+
+func lessIndex(a, b []int) bool {
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// Whether to include this field in the gql schema, which it isn't if it or
+// any of the structs it's promoted through is excluded
+func includeStructField(f structField, exclude ExcludeFieldTag) bool {
+	for _, e := range f.embedded {
+		if isExcluded(e, exclude) {
+			return false
+		}
+	}
+	return !isExcluded(f.StructField, exclude)
+}
+
+// Get the field of the struct value v at the index sequence index. The value
+// is invalid if the field is promoted through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for k, i := range index {
+		if k > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// Get the field of the struct value v at the index sequence index, for
+// setting it, allocating the nil embedded pointers it's promoted through
+func allocFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for k, i := range index {
+		if k > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf(
+						"cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, nil
+}
+
+// Get the go path segment of the field, through the structs it's promoted
+// through, for example .Base.ID
+func (f structField) goPath() string {
+	path := ""
+	for _, e := range f.embedded {
+		path += "." + e.Name
+	}
+	return path + "." + f.Name
+}
+
+// Whether the string option of a tag applies to fields of type t, which it
+// does for strings, numbers and bools, or pointers to these, the same as it
+// does in encoding/json
+func isQuotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// EnumValuesMethod is the name of the method by which go types declare that
+// they're enums. A type that has a Values method, which returns a slice of
+// all the values of the type, is reflected as a graphql enum of these values.
+// For example
+//
+//	type Status string
+//
+//	func (Status) Values() []Status {
+//		return []Status{Active, Inactive}
+//	}
+//
+// The method is called on the zero value of the type.
+const EnumValuesMethod = "Values"
+
+// NewEnum returns a type map entry that maps the go type of the given values,
+// a slice, to a graphql enum of these values. Use it for enum types that do
+// not have a Values method of their own. For example
+//
+//	typeMap[reflect.TypeOf(Active)] = reflector.NewEnum([]Status{Active, Inactive})
+func NewEnum(values interface{}) GqlOutputAndResolver {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("Cannot make an enum of %T, a slice of values is needed", values))
+	}
+	enum, err := newEnum(GoTypeNamer(v.Type().Elem(), ""), v, typeDescription(v.Type().Elem()))
+	if err != nil {
+		panic(err.Error())
+	}
+	return GqlOutputAndResolver{
+		Output:   enum,
+		Resolver: convertingResolver(enumConverter(v)),
+	}
+}
+
+// Reflect the go type t as an enum if it has a Values method, nil otherwise
+func (r *reflection) reflectEnum(t reflect.Type) *graphql.Enum {
+	if enum, exists := r.cache.enums[t]; exists {
+		return enum
+	}
+	values, isEnum := enumValues(t)
+	if !isEnum {
+		return nil
+	}
+	enum, err := newEnum(r.typeName(t, GqlName(t.Name())), values, typeDescription(t))
+	if err != nil {
+		r.fail(t, "%s", err)
+	}
+	r.cache.enums[t] = enum
+	r.added(t, func() { delete(r.cache.enums, t) })
+	return enum
+}
+
+// Get the values of t by its Values method, if it has one
+func enumValues(t reflect.Type) (reflect.Value, bool) {
+	if t.Kind() == reflect.Interface {
+		return reflect.Value{}, false
+	}
+	m, exists := t.MethodByName(EnumValuesMethod)
+	if !exists || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 ||
+		m.Type.Out(0) != reflect.SliceOf(t) {
+		return reflect.Value{}, false
+	}
+	return m.Func.Call([]reflect.Value{reflect.Zero(t)})[0], true
+}
+
+// Make a graphql enum, named name and described by description, of the go
+// values of the slice values.
+// The values are named by their String method if they have one, otherwise
+// they must be strings, and are named by themselves.
+func newEnum(
+	name GqlName,
+	values reflect.Value,
+	description string,
+) (*graphql.Enum, error) {
+	enumValues := make(graphql.EnumValueConfigMap)
+	for i := 0; i < values.Len(); i++ {
+		v := values.Index(i)
+		var valueName string
+		if stringer, ok := v.Interface().(fmt.Stringer); ok {
+			valueName = stringer.String()
+		} else if v.Kind() == reflect.String {
+			valueName = v.String()
+		} else {
+			return nil, fmt.Errorf("Cannot name the value %v of enum %s. "+
+				"Enum values must either be strings or have a String method", v, name)
+		}
+		gqlName := string(sanitizeName(valueName))
+		if _, exists := enumValues[gqlName]; exists {
+			return nil, fmt.Errorf("Enum %s has more than one value named %s", name, gqlName)
+		}
+		enumValues[gqlName] = &graphql.EnumValueConfig{
+			Value: v.Interface(),
+		}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{
+		Name:        string(name),
+		Values:      enumValues,
+		Description: description,
+	}), nil
+}
+
+// Get the converter of the values of an enum, the go values of the slice
+// values, which fails to convert any other value of their type, rather than
+// let graphql serialize it to null
+func enumConverter(values reflect.Value) converter {
+	t := values.Type().Elem()
+	valid := make(map[interface{}]bool, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		valid[values.Index(i).Interface()] = true
+	}
+	return func(v reflect.Value) (interface{}, error) {
+		v = indirectValue(v)
+		if !v.IsValid() || v.Type() != t {
+			// Such as a value of a map source
+			return valueInterface(v), nil
+		}
+		if !valid[v.Interface()] {
+			return nil, fmt.Errorf("invalid value %#v of enum %s", v.Interface(), t)
+		}
+		return v.Interface(), nil
+	}
+}
@@ -0,0 +1,167 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ReflectionError is a problem found while reflecting a go type
+type ReflectionError struct {
+	// Path is the go path to the problem, starting at the reflected type,
+	// for example Order.Items[].Price
+	Path string
+	// Type is the offending go type, if any
+	Type reflect.Type
+	// Message describes the problem
+	Message string
+}
+
+func (e *ReflectionError) Error() string {
+	if e.Type == nil {
+		return fmt.Sprintf("%s: %s", e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Path, e.Type, e.Message)
+}
+
+// ReflectionErrors are all the problems found while reflecting a go type
+type ReflectionErrors []*ReflectionError
+
+func (errs ReflectionErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ReflectTypeFqE is like ReflectTypeFq, but rather than panicking on the
+// first problem, it returns all the problems found in t as ReflectionErrors.
+// It also reports problems that ReflectTypeFq leaves to the creation of the
+// schema, such as objects without fields.
+func ReflectTypeFqE(
+	name GqlName,
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (graphql.Type, error) {
+	return newReflector(typeMap, exclude, opts).TypeFq(name, t)
+}
+
+// ReflectFieldsFqE is like ReflectFieldsFq, returning errors the same way as
+// ReflectTypeFqE does
+func ReflectFieldsFqE(
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (graphql.Fields, error) {
+	return newReflector(typeMap, exclude, opts).Fields(t)
+}
+
+// ReflectFieldFqE is like ReflectFieldFq, returning errors the same way as
+// ReflectTypeFqE does
+func ReflectFieldFqE(
+	name GqlName,
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (*graphql.Field, error) {
+	return newReflector(typeMap, exclude, opts).Field(name, t)
+}
+
+// ReflectInputTypeFqE is like ReflectInputTypeFq, returning errors the same
+// way as ReflectTypeFqE does
+func ReflectInputTypeFqE(
+	name GqlName,
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (graphql.Input, error) {
+	return newReflector(typeMap, exclude, opts).InputType(name, t)
+}
+
+// ReflectArgsFqE is like ReflectArgsFq, returning errors the same way as
+// ReflectTypeFqE does
+func ReflectArgsFqE(
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (graphql.FieldConfigArgument, error) {
+	return newReflector(typeMap, exclude, opts).Args(t)
+}
+
+// The root of the go paths of problems found in t
+func rootPath(name GqlName, t reflect.Type) string {
+	if n := indirectType(t).Name(); n != "" {
+		return n
+	}
+	if name != "" {
+		return string(name)
+	}
+	return t.String()
+}
+
+// Make the reflection collect the problems it finds rather than panic, with
+// go paths that start at root
+func (r *reflection) collectErrors(root string) {
+	r.errs = ReflectionErrors{}
+	r.path = []string{root}
+}
+
+// Get the problems collected by the reflection, if any
+func (r *reflection) err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs
+}
+
+// Add segment to the go path of the reflection, until the returned function
+// is called
+func (r *reflection) at(segment string) func() {
+	r.path = append(r.path, segment)
+	return func() {
+		r.path = r.path[:len(r.path)-1]
+	}
+}
+
+// Fail on a problem with the go type t, which may be nil, at the current go
+// path: panic, unless the reflection collects errors
+func (r *reflection) fail(t reflect.Type, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	r.failed = true
+	if r.errs == nil {
+		panic(msg)
+	}
+	r.report(t, msg)
+}
+
+// Like fail, but only for reflections that collect errors. It's used for
+// problems that are otherwise left to the creation of the schema.
+func (r *reflection) check(ok bool, t reflect.Type, format string, args ...interface{}) {
+	if !ok && r.errs != nil {
+		r.report(t, fmt.Sprintf(format, args...))
+	}
+}
+
+func (r *reflection) report(t reflect.Type, msg string) {
+	r.failed = true
+	r.errs = append(r.errs, &ReflectionError{
+		Path:    strings.Join(r.path, ""),
+		Type:    t,
+		Message: msg,
+	})
+}
+
+// Check that the field f is named by a valid graphql name
+func (r *reflection) checkName(f structField, name GqlName) {
+	r.check(sanitizeName(string(name)) == name, f.Type,
+		"%q is not a valid graphql name", name)
+}
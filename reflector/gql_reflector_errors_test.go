@@ -0,0 +1,88 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorsItem struct {
+	Price   chan int          `json:"price"`
+	Options map[string]func() `json:"options"`
+}
+
+type errorsEmpty struct {
+	hidden string
+}
+
+type errorsOrder struct {
+	ID    int          `json:"id"`
+	Items []errorsItem `json:"items"`
+	Empty errorsEmpty  `json:"empty"`
+	Bad   string       `json:"bad.name"`
+}
+
+type errorsArgs struct {
+	Limit int `json:"limit" gqldefault:"ten"`
+}
+
+func TestReflectionErrors(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+
+	gqlt, err := ReflectTypeFqE("", reflect.TypeOf(errorsOrder{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Nil(gqlt)
+	req.IsType(ReflectionErrors{}, err)
+	errs := err.(ReflectionErrors)
+	req.Len(errs, 4)
+	as.Equal("errorsOrder.Items[].Price", errs[0].Path)
+	as.Equal(reflect.TypeOf(make(chan int)), errs[0].Type)
+	as.Equal("errorsOrder.Items[].Options[]", errs[1].Path)
+	as.Equal("errorsOrder.Empty", errs[2].Path)
+	as.Equal("errorsOrder.Bad (string): \"bad.name\" is not a valid graphql name", errs[3].Error())
+	as.Contains(err.Error(), "errorsOrder.Items[].Price (chan int): Unsupported GQL kind chan")
+
+	// The panicking variant still panics on the first problem
+	as.PanicsWithValue("Unsupported GQL kind chan. Field: ", func() {
+		ReflectTypeFq("", reflect.TypeOf(errorsOrder{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	})
+
+	_, err = ReflectInputTypeFqE("", reflect.TypeOf(errorsItem{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.EqualError(err, "errorsItem.Price (chan int): Unsupported GQL kind chan. Field: \n"+
+		"errorsItem.Options[] (func()): Unsupported GQL kind func. Field: ")
+
+	_, err = ReflectArgsFqE(reflect.TypeOf(errorsArgs{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Contains(err.Error(), `errorsArgs.Limit (int): Invalid int default value "ten" of Limit`)
+
+	fields, err := ReflectFieldsFqE(reflect.TypeOf(schemaUser{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Nil(err)
+	as.Len(fields, 2)
+
+	_, _, err = BuildSchema(struct {
+		Order errorsOrder `json:"order"`
+	}{}, nil)
+	as.Contains(err.Error(), "Query.Order.Items[].Price (chan int)")
+}
+
+func TestReflectionErrorsCached(t *testing.T) {
+	type Holder struct {
+		errorsItem
+		ID int `json:"id"`
+	}
+	as := assert.New(t)
+
+	rf := New()
+	for i := 0; i < 2; i++ {
+		gqlt, err := rf.Type(errorsItem{})
+		as.Nil(gqlt)
+		as.Contains(fmt.Sprint(err), "errorsItem.Price (chan int): Unsupported GQL kind chan")
+	}
+	gqlt, err := rf.Type(Holder{})
+	as.Nil(gqlt)
+	as.Contains(fmt.Sprint(err), "Holder.errorsItem.Price (chan int): Unsupported GQL kind chan")
+	// Types of failed reflections are not left in the cache
+	as.Empty(rf.Types())
+}
@@ -0,0 +1,94 @@
+package reflector
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FieldCasing is the casing strategy by which the names of struct fields are
+// turned into the names of graphql fields
+type FieldCasing int
+
+const (
+	// KeepCase keeps the names of fields as they are given by their tags,
+	// or by their go names (see WithUntaggedFields).
+	KeepCase FieldCasing = iota
+	// CamelCase names fields in lower camel case, so both user_id and UserID
+	// become userId.
+	CamelCase
+	// SnakeCase names fields in snake case, so both userId and UserID become
+	// user_id.
+	SnakeCase
+)
+
+// DefaultTagKey is the struct field tag that names graphql fields by default
+const DefaultTagKey = "json"
+
+// fieldNaming is the way the fields of structs are named in graphql
+type fieldNaming struct {
+	// The key of the struct field tag that names fields
+	tagKey string
+	// Whether untagged exported fields are named by their go names, rather
+	// than left out
+	goNames bool
+	casing  FieldCasing
+}
+
+var defaultFieldNaming = fieldNaming{tagKey: DefaultTagKey}
+
+// Apply the casing strategy to the field name s
+func (c FieldCasing) apply(s string) GqlName {
+	switch c {
+	case CamelCase:
+		words := splitWords(s)
+		for i, word := range words {
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+			}
+		}
+		return GqlName(strings.Join(words, ""))
+	case SnakeCase:
+		words := splitWords(s)
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+		return GqlName(strings.Join(words, "_"))
+	}
+	return GqlName(s)
+}
+
+// The graphql name of the go method m, which is in lower camel case unless
+// another casing strategy is given
+func (c FieldCasing) method(m string) GqlName {
+	if c == KeepCase {
+		return lowerCamelCase(m)
+	}
+	return c.apply(m)
+}
+
+// Split the name s into its words, which are separated by underscores and
+// dashes, or start with an upper case letter. Acronyms are single words, for
+// example HTTPServerID is split into HTTP, Server and ID.
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		end := i == len(runes) || runes[i] == '_' || runes[i] == '-'
+		if !end && i > start && unicode.IsUpper(runes[i]) &&
+			(!unicode.IsUpper(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+		if end {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return words
+}
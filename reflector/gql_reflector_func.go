@@ -0,0 +1,203 @@
+package reflector
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ReflectFunc is a shorthand method for invoking ReflectFuncFq.
+// The field is named after the type the function returns, and uses the
+// default type mapping and no exclude tags at all.
+func ReflectFunc(fn interface{}, opts ...Option) *graphql.Field {
+	if fn == nil {
+		panic("Cannot infer type of nil function")
+	}
+	t := reflect.TypeOf(fn)
+	if t.Kind() != reflect.Func || t.NumOut() == 0 {
+		panic(fmt.Sprintf("Cannot reflect %s, a function with a result is needed", t))
+	}
+	return ReflectFuncFq(
+		GqlName(indirectType(t.Out(0)).Name()),
+		fn,
+		GetDefaultTypeMap(),
+		ExcludeFieldTag(""),
+		opts...,
+	)
+}
+
+// ReflectFuncFq returns a graphql field, named name, that resolves by calling
+// the go function fn.
+// fn may take a context.Context, which is the context of the resolve params,
+// followed by an args struct (or a pointer to one), which is reflected by
+// ReflectArgsFq and decoded by DecodeArgs. Both are optional.
+// fn must return a result, which is reflected by ReflectTypeFq, optionally
+// followed by an error. For example
+//
+//	func(ctx context.Context, args GetUserArgs) (*User, error)
+func ReflectFuncFq(
+	name GqlName,
+	fn interface{},
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) *graphql.Field {
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectFunc(name, reflect.ValueOf(fn))
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// funcSignature describes a function that can be reflected as a field
+type funcSignature struct {
+	takesContext bool
+	// nil if the function takes no args struct
+	argsType reflect.Type
+	// The types of the positional parameters, when the function takes
+	// these rather than an args struct
+	params       []reflect.Type
+	resultType   reflect.Type
+	returnsError bool
+}
+
+// Parse the signature of the function type t, failing if it can't be reflected
+// as a field. params is the number of positional parameters t takes instead of
+// an args struct, if any.
+func parseFuncSignature(t reflect.Type, params int) (funcSignature, error) {
+	var sig funcSignature
+	if t.Kind() != reflect.Func {
+		return sig, fmt.Errorf("%s is not a function", t)
+	}
+	in := 0
+	sig.takesContext = in < t.NumIn() && t.In(in) == contextType
+	if sig.takesContext {
+		in++
+	}
+	if params > 0 {
+		for ; in < t.NumIn() && len(sig.params) < params; in++ {
+			sig.params = append(sig.params, t.In(in))
+		}
+	} else if in < t.NumIn() && indirectType(t.In(in)).Kind() == reflect.Struct {
+		sig.argsType = t.In(in)
+		in++
+	}
+	sig.returnsError = t.NumOut() == 2 && t.Out(1) == errorType
+	if in != t.NumIn() || len(sig.params) != params || t.IsVariadic() ||
+		t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && !sig.returnsError) {
+		expected := "[args struct]"
+		if params > 0 {
+			expected = fmt.Sprintf("%d parameters", params)
+		}
+		return sig, fmt.Errorf(
+			"unsupported function %s, expected func([context.Context], %s) (result, [error])",
+			t, expected)
+	}
+	sig.resultType = t.Out(0)
+	return sig, nil
+}
+
+// Reflect a field, named name, that resolves by calling the function fn
+func (r *reflection) reflectFunc(name GqlName, fn reflect.Value) *graphql.Field {
+	if !fn.IsValid() {
+		r.fail(nil, "Cannot reflect field %s: nil function", name)
+		return &graphql.Field{Name: string(name), Type: graphql.String}
+	}
+	sig, err := parseFuncSignature(fn.Type(), 0)
+	if err != nil {
+		r.fail(fn.Type(), "Cannot reflect field %s: %s", name, err)
+		return &graphql.Field{Name: string(name), Type: graphql.String}
+	}
+	r.claimNames(fn.Type())
+	return r.reflectCall(name, name, sig, nil,
+		func(p graphql.ResolveParams, in []reflect.Value) ([]reflect.Value, error) {
+			return fn.Call(in), nil
+		})
+}
+
+// caller calls a function with the given input values, as part of resolving p
+type caller func(p graphql.ResolveParams, in []reflect.Value) ([]reflect.Value, error)
+
+// Reflect a field, named name, that resolves by calling a function of the
+// signature sig. typeName is the name to use for the result type if it has no
+// name of its own. paramNames are the names of the arguments of the positional
+// parameters of the function, if any.
+func (r *reflection) reflectCall(
+	name GqlName,
+	typeName GqlName,
+	sig funcSignature,
+	paramNames []GqlName,
+	call caller,
+) *graphql.Field {
+	gqlType := r.reflectType(typeName, sig.resultType)
+	if r.nullability == InferNonNull && !canBeNil(sig.resultType) {
+		gqlType = nonNull(gqlType)
+	}
+	var args graphql.FieldConfigArgument
+	if sig.argsType != nil {
+		args = r.reflectArgs(sig.argsType)
+	}
+	for i, paramType := range sig.params {
+		if args == nil {
+			args = make(graphql.FieldConfigArgument)
+		}
+		argType := r.reflectInputType(typeName+"_"+paramNames[i], paramType)
+		if r.nullability == InferNonNull && !canBeNil(paramType) {
+			argType = nonNull(argType)
+		}
+		args[string(paramNames[i])] = &graphql.ArgumentConfig{Type: argType}
+	}
+	resolveResult := emptyListResolver(sig.resultType, gqlType,
+		intRangeResolver(sig.resultType, gqlType, r.getResolver(sig.resultType)))
+	d := decoder{&r.options}
+
+	return &graphql.Field{
+		Name: string(name),
+		Type: gqlType,
+		Args: args,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			values := make([]reflect.Value, 0, 2+len(sig.params))
+			if sig.takesContext {
+				ctx := p.Context
+				if ctx == nil {
+					ctx = context.Background()
+				}
+				values = append(values, reflect.ValueOf(ctx))
+			}
+			if sig.argsType != nil {
+				args := reflect.New(indirectType(sig.argsType))
+				if err := d.decodeFields("", p.Args, args.Elem()); err != nil {
+					return nil, err
+				}
+				if sig.argsType.Kind() != reflect.Ptr {
+					args = args.Elem()
+				}
+				values = append(values, args)
+			}
+			for i, paramType := range sig.params {
+				param := reflect.New(paramType).Elem()
+				name := string(paramNames[i])
+				if err := d.decodeValue(name, p.Args[name], param); err != nil {
+					return nil, err
+				}
+				values = append(values, param)
+			}
+
+			out, err := call(p, values)
+			if err != nil {
+				return nil, err
+			}
+			if sig.returnsError && !out[1].IsNil() {
+				return nil, out[1].Interface().(error)
+			}
+			// Resolve the result the same way as a struct field holding it
+			p.Source = valueSource{value: out[0], parent: p.Source}
+			return resolveResult(p)
+		},
+	}
+}
@@ -0,0 +1,172 @@
+package reflector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+type funcArgs struct {
+	ID int `json:"id"`
+}
+
+type funcResult struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type funcContextKey struct{}
+
+func TestReflectFunc(t *testing.T) {
+	f := ReflectFunc(func(ctx context.Context, args funcArgs) (*funcResult, error) {
+		if args.ID == 0 {
+			return nil, errors.New("no such result")
+		}
+		return &funcResult{ID: args.ID, Name: ctx.Value(funcContextKey{}).(string)}, nil
+	})
+	assert.Equal(t, "funcResult", f.Name)
+	assert.Equal(t, "funcResult", f.Type.Name())
+	assert.Equal(t, "Int", f.Args["id"].Type.String())
+
+	assertFuncQuery(t, *f, "r", "(id: 5) {id name}", `{"data":{"r":{"id":5,"name":"from context"}}}`, "")
+	assertFuncQuery(t, *f, "r", "{id name}", "", "no such result")
+}
+
+func TestReflectFuncResults(t *testing.T) {
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	f := ReflectFuncFq("t", func() time.Time {
+		return tm
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	assertQuery(t, *f, "t", "", `{"data":{"t":"2009-11-10T23:00:00Z"}}`, "")
+
+	f = ReflectFuncFq("m", func(args *funcArgs) map[string]int {
+		return map[string]int{"b": 2, "a": args.ID}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull))
+	assert.Equal(t, "[m_entry]", f.Type.String())
+	assertQuery(t, *f, "m", "(id: 1) {key value}",
+		`{"data":{"m":[{"key":"a","value":1},{"key":"b","value":2}]}}`, "")
+
+	f = ReflectFuncFq("p", func() *int {
+		return nil
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	assertQuery(t, *f, "p", "", `{"data":{"p":null}}`, "")
+}
+
+func TestReflectFuncUnsupported(t *testing.T) {
+	as := assert.New(t)
+	as.Panics(func() { ReflectFunc(5) })
+	as.Panics(func() { ReflectFunc(func() {}) })
+	as.Panics(func() { ReflectFunc(func(int) string { return "" }) })
+	as.Panics(func() { ReflectFunc(func() (string, int) { return "", 0 }) })
+	as.Panics(func() { ReflectFunc(func(funcArgs, context.Context) string { return "" }) })
+}
+
+// Like assertQuery, with a context holding a value for funcContextKey
+func assertFuncQuery(
+	t *testing.T,
+	f graphql.Field,
+	rootQuery,
+	query,
+	expectedResult,
+	expectedError string,
+) {
+	resolve := f.Resolve
+	f.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+		p.Context = context.WithValue(context.Background(), funcContextKey{}, "from context")
+		return resolve(p)
+	}
+	assertQuery(t, f, rootQuery, query, expectedResult, expectedError)
+}
+
+type methodsUser struct {
+	First string `json:"first"`
+	Last  string `json:"last"`
+	items map[string]float64
+}
+
+func (u methodsUser) FullName() string {
+	return u.First + " " + u.Last
+}
+
+func (u *methodsUser) Total(ctx context.Context, currency string, rate float64) (float64, error) {
+	if currency != "USD" {
+		return 0, errors.New("unsupported currency " + currency)
+	}
+	total := 0.0
+	for _, price := range u.items {
+		total += price * rate
+	}
+	return total, nil
+}
+
+func (u methodsUser) Item(args struct {
+	Name string `json:"name"`
+}) *float64 {
+	if price, exists := u.items[args.Name]; exists {
+		return &price
+	}
+	return nil
+}
+
+func (u methodsUser) GqlMethodFields() []MethodField {
+	return []MethodField{
+		{Method: "FullName"},
+		{Method: "Total", Args: []GqlName{"currency", "rate"}},
+		{Method: "Item", Name: "price_of"},
+	}
+}
+
+func TestMethodFields(t *testing.T) {
+	gqlt := ReflectType(methodsUser{}, WithNullability(InferNonNull))
+	fields := gqlt.(*graphql.Object).Fields()
+	assert.Equal(t, "String!", fields["fullName"].Type.String())
+	assert.Equal(t, "Float!", fields["total"].Type.String())
+	assert.Equal(t, "Float", fields["price_of"].Type.String())
+
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return methodsUser{
+				First: "John",
+				Last:  "Doe",
+				items: map[string]float64{"a": 1, "b": 2},
+			}, nil
+		},
+	}
+	assertQuery(t, f, "u", `{
+		first
+		fullName
+		total(currency: "USD", rate: 2)
+		price_of(name: "b")
+		none: price_of(name: "c")
+	}`, `{"data":{"u":{
+		"first": "John",
+		"fullName": "John Doe",
+		"total": 6,
+		"price_of": 2,
+		"none": null
+	}}}`, "")
+	assertQuery(t, f, "u", `{total(currency: "EUR", rate: 1)}`, "", "unsupported currency EUR")
+}
+
+type badMethodsUser struct {
+	A string `json:"a"`
+}
+
+func (u badMethodsUser) A2(a, b string) string {
+	return a + b
+}
+
+func (u badMethodsUser) GqlMethodFields() []MethodField {
+	return []MethodField{{Method: "A2", Name: "a"}}
+}
+
+func TestMethodFieldsErrors(t *testing.T) {
+	assert.PanicsWithValue(t,
+		"Method A2 of reflector.badMethodsUser and another field are both named a",
+		func() { ReflectType(badMethodsUser{}) })
+}
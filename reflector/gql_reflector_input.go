@@ -0,0 +1,215 @@
+package reflector
+
+import (
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// DefaultInputSuffix is the suffix added to the names of input objects, so the
+// input and output types of the same struct may be in the same schema.
+const DefaultInputSuffix = "Input"
+
+// ReflectInputType is a shorthand method for invoking ReflectInputTypeFq.
+// It derives the most basic fields from the given instance (typically a struct)
+// such as the name of the struct, and uses the default type mapping and no
+// exclude tags at all.
+func ReflectInputType(instance interface{}, opts ...Option) graphql.Input {
+	if instance == nil {
+		panic("Cannot infer type of nil instance")
+	}
+	t := reflect.TypeOf(instance)
+	return ReflectInputTypeFq(
+		GqlName(indirectType(t).Name()),
+		t,
+		GetDefaultTypeMap(),
+		ExcludeFieldTag(""),
+		opts...,
+	)
+}
+
+// ReflectInputTypeFq returns a Graphql input type that represents the go
+// reflect.Type structure (recorsively), for use in arguments.
+// Structs are reflected as input objects, named the same way as objects are
+// by ReflectTypeFq, with an "Input" suffix (see WithInputSuffix).
+func ReflectInputTypeFq(
+	name GqlName,
+	t reflect.Type,
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) graphql.Input {
+	r := newReflection(typeMap, exclude, opts)
+	defer r.done()
+	return r.reflectInputType(name, t)
+}
+
+// Reflect the go type t as an input type. name is the name to use for t if it
+// has no name of its own.
+func (r *reflection) reflectInputType(name GqlName, t reflect.Type) graphql.Input {
+	r.claimNames(t)
+	if m := r.unmarshalingOf(t); m != notMarshaled {
+		return m.scalar()
+	}
+	if m, exists := r.typeMap[t]; exists {
+		if m.Input != nil {
+			return m.Input
+		}
+		if _, isLeaf := m.Output.(graphql.Leaf); isLeaf {
+			r.checkIntFits(t, m.Output)
+			return m.Output
+		}
+		r.fail(t, "No GQL input type for %s. Output type: %s", t, m.Output)
+		return graphql.String
+	}
+	if enum := r.reflectEnum(t); enum != nil {
+		return enum
+	}
+	if isScanner(t) {
+		// Any input value goes, as long as it can be scanned
+		return JSON
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return graphql.String
+	case reflect.Interface:
+		// Any input value goes
+		return JSON
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		r.checkIntFits(t, graphql.Int)
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Struct:
+		return r.reflectInputObject(name, t)
+	case reflect.Slice, reflect.Array:
+		defer r.at("[]")()
+		return graphql.NewList(r.reflectInputType(name, t.Elem()))
+	case reflect.Ptr:
+		return r.reflectInputType(name, t.Elem())
+	case reflect.Map:
+		return graphql.NewList(r.reflectInputMapEntry(name, t))
+	case reflect.Invalid:
+		r.fail(t, "Invalid GQL kind %s. Field: %s", t.Kind(), t.Name())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		r.fail(t, "Unsupported GQL kind %s. Field: %s", t.Kind(), t.Name())
+	default:
+		r.fail(t, "Unknown GO kind %s. Field: %s", t.Kind(), t.Name())
+	}
+	// Carry on collecting errors
+	return graphql.String
+}
+
+// Reflect the struct type t into a graphql input object, the same way
+// reflectObject does for objects
+func (r *reflection) reflectInputObject(
+	name GqlName,
+	t reflect.Type,
+) *graphql.InputObject {
+	if obj, exists := r.cache.inputs[t]; exists {
+		return obj
+	}
+	// Nested types are named after the name without the suffix
+	name = r.namer(t, name)
+	inputName := r.registerTypeName(t, name+GqlName(r.inputSuffix))
+	var fields graphql.InputObjectConfigFieldMap
+	obj := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        string(inputName),
+		Description: typeDescription(t),
+		Fields: graphql.InputObjectConfigFieldMapThunk(
+			func() graphql.InputObjectConfigFieldMap {
+				return fields
+			}),
+	})
+	r.cache.inputs[t] = obj
+	r.added(t, func() { delete(r.cache.inputs, t) })
+	fields = r.reflectInputFields(name, t)
+	r.check(len(fields) > 0, t, "input object %s has no fields, "+
+		"since none of the fields of %s is exported, tagged and not excluded", inputName, t)
+	return obj
+}
+
+// Reflect the fields of the struct type t, which is named parent in graphql
+func (r *reflection) reflectInputFields(
+	parent GqlName,
+	t reflect.Type,
+) graphql.InputObjectConfigFieldMap {
+	fields := make(graphql.InputObjectConfigFieldMap)
+	for _, f := range structFields(t, r.naming) {
+		if includeStructField(f, r.exclude) {
+			name := f.name
+			pop := r.at(f.goPath())
+			r.checkName(f, name)
+			fields[string(name)] = &graphql.InputObjectFieldConfig{
+				Type:        r.applyNullability(f, r.reflectInputFieldType(parent, f)),
+				Description: f.Tag.Get(GqlDescTagName),
+			}
+			pop()
+		}
+	}
+	return fields
+}
+
+// Reflect the input type of the field f of a struct type, which is named parent
+// in graphql
+func (r *reflection) reflectInputFieldType(parent GqlName, f structField) graphql.Input {
+	switch {
+	case f.Tag.Get(GqlMapTagName) == GqlMapJSON:
+		return JSON
+	case f.quoted:
+		return graphql.String
+	case r.isTimeField(f):
+		format, err := getTimeFormat(f.StructField)
+		if err != nil {
+			r.fail(f.Type, "%s", err)
+		}
+		return format.scalar
+	}
+	if scalar := r.intFieldScalar(f); scalar != nil {
+		return r.reflectIntType(f.Type, scalar).(graphql.Input)
+	}
+	return r.reflectInputType(parent+"_"+f.name, f.Type)
+}
+
+// Reflect the input object type of the entries of the map type t
+func (r *reflection) reflectInputMapEntry(
+	name GqlName,
+	t reflect.Type,
+) *graphql.InputObject {
+	if obj, exists := r.cache.inputs[t]; exists {
+		return obj
+	}
+	name = r.namer(t, name) + "_entry"
+	inputName := r.registerTypeName(t, name+GqlName(r.inputSuffix))
+	fields := make(graphql.InputObjectConfigFieldMap)
+	obj := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: string(inputName),
+		Fields: graphql.InputObjectConfigFieldMapThunk(
+			func() graphql.InputObjectConfigFieldMap {
+				return fields
+			}),
+	})
+	r.cache.inputs[t] = obj
+	r.added(t, func() { delete(r.cache.inputs, t) })
+
+	// The key is always required, since a map can't do without it
+	pop := r.at("[key]")
+	fields["key"] = &graphql.InputObjectFieldConfig{
+		Type: nonNull(r.reflectInputType(name+"_key", t.Key())),
+	}
+	pop()
+	pop = r.at("[]")
+	value := r.reflectInputType(name+"_value", t.Elem())
+	pop()
+	if r.nullability == InferNonNull && !canBeNil(t.Elem()) {
+		value = nonNull(value)
+	}
+	fields["value"] = &graphql.InputObjectFieldConfig{
+		Type: value,
+	}
+	return obj
+}
@@ -0,0 +1,189 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Values of the gqlmap struct field tag, which sets how map fields are
+// reflected
+const (
+	// GqlMapEntries reflects a map as a list of {key, value} objects, sorted
+	// by key. This is the default.
+	GqlMapEntries = "entries"
+	// GqlMapJSON reflects a map as an opaque JSON scalar
+	GqlMapJSON = "json"
+)
+
+// mapEntry is the source of the objects that represent map entries
+type mapEntry struct {
+	Key   interface{} `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// Reflect the object type of the entries of the map type t
+func (r *reflection) reflectMapEntry(name GqlName, t reflect.Type) graphql.Type {
+	if obj, exists := r.cache.objects[t]; exists {
+		return obj
+	}
+	name = r.registerTypeName(t, r.namer(t, name)+"_entry")
+	fields := make(graphql.Fields)
+	obj := graphql.NewObject(graphql.ObjectConfig{
+		Name: string(name),
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return fields
+		}),
+	})
+	r.cache.objects[t] = obj
+	r.added(t, func() { delete(r.cache.objects, t) })
+
+	pop := r.at("[key]")
+	key := r.reflectField("key", t.Key(), name+"_key")
+	pop()
+	pop = r.at("[]")
+	value := r.reflectField("value", t.Elem(), name+"_value")
+	pop()
+	if r.nullability == InferNonNull {
+		key.Type = nonNull(key.Type)
+		if !canBeNil(t.Elem()) {
+			value.Type = nonNull(value.Type)
+			value.Resolve = emptyListResolver(t.Elem(), value.Type, value.Resolve)
+		}
+	}
+	fields["key"] = key
+	fields["value"] = value
+	return obj
+}
+
+// converter converts a go value into the value graphql expects to resolve
+// for it
+type converter func(v reflect.Value) (interface{}, error)
+
+// Get the converter for values of type t, nil if these values need no
+// conversion at all. Maps need to be converted into lists of entries, and so
+// are lists of maps etc. Marshalers are converted into the values they marshal
+// into (see WithMarshalers), and enum values are checked to be valid.
+func (o *options) getConverter(t reflect.Type) converter {
+	if m := o.marshalingOf(t); m != notMarshaled {
+		return m.convert
+	}
+	if _, exists := o.typeMap[t]; exists {
+		return nil
+	}
+	if values, isEnum := enumValues(t); isEnum {
+		return enumConverter(values)
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return mapEntries
+	case reflect.Ptr:
+		convert := o.getConverter(t.Elem())
+		if convert == nil {
+			return nil
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+				return nil, nil
+			}
+			return convert(indirectValue(v))
+		}
+	case reflect.Slice, reflect.Array:
+		convert := o.getConverter(t.Elem())
+		if convert == nil {
+			return nil
+		}
+		return func(v reflect.Value) (interface{}, error) {
+			v = indirectValue(v)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return valueInterface(v), nil
+			}
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				return nil, nil
+			}
+			converted := make([]interface{}, v.Len())
+			for i := range converted {
+				var err error
+				if converted[i], err = convert(v.Index(i)); err != nil {
+					return nil, err
+				}
+			}
+			return converted, nil
+		}
+	}
+	return nil
+}
+
+// Get the value held by v, nil if v is invalid or nil
+func valueInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+// Resolves fields by converting their value with convert
+func convertingResolver(convert converter) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		value := GetValueFromResolveParams(p)
+		if !value.IsValid() || value.Kind() == reflect.Ptr {
+			// Only nil pointers are not dereferenced
+			return nil, nil
+		}
+		return convert(value)
+	}
+}
+
+// Convert the map v into a list of its entries, sorted by key. Nil maps
+// convert to nil, the same as nil slices.
+func mapEntries(v reflect.Value) (interface{}, error) {
+	v = indirectValue(v)
+	if v.Kind() != reflect.Map || v.IsNil() {
+		// Not a map after all, such as a value of a map source
+		return valueInterface(v), nil
+	}
+	keys := v.MapKeys()
+	sortValues(keys)
+	entries := make([]mapEntry, len(keys))
+	for i, k := range keys {
+		entries[i] = mapEntry{
+			Key:   k.Interface(),
+			Value: v.MapIndex(k).Interface(),
+		}
+	}
+	return entries, nil
+}
+
+// Sort values of the same type, in their natural order if they have one
+func sortValues(values []reflect.Value) {
+	if len(values) == 0 {
+		return
+	}
+	var less func(i, j int) bool
+	switch values[0].Kind() {
+	case reflect.String:
+		less = func(i, j int) bool { return values[i].String() < values[j].String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(i, j int) bool { return values[i].Int() < values[j].Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		less = func(i, j int) bool { return values[i].Uint() < values[j].Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(i, j int) bool { return values[i].Float() < values[j].Float() }
+	case reflect.Bool:
+		less = func(i, j int) bool { return !values[i].Bool() && values[j].Bool() }
+	default:
+		less = func(i, j int) bool {
+			return fmt.Sprint(values[i].Interface()) < fmt.Sprint(values[j].Interface())
+		}
+	}
+	sort.Slice(values, less)
+}
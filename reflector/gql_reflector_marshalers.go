@@ -0,0 +1,247 @@
+package reflector
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// MarshalerPrecedence sets whether go types that marshal themselves, such as
+// net.IP, UUIDs and IDs, are reflected as the values they marshal into, and
+// whether the type map takes precedence over that.
+//
+// Types that implement encoding.TextMarshaler are reflected as String fields,
+// and types that implement json.Marshaler as JSON fields, resolving to the
+// text or the JSON value they marshal into. Arrays and structs that implement
+// fmt.Stringer, and none of the above, are reflected as String fields that
+// resolve to their String method. Input values are parsed by
+// encoding.TextUnmarshaler, or else by json.Unmarshaler.
+// Structs with tagged fields are reflected as objects all the same, and so are
+// structs with exported fields, unless they implement
+// encoding.TextMarshaler. url.URL is mapped to String by the default type map.
+type MarshalerPrecedence int
+
+const (
+	// MarshalersAfterTypeMap reflects marshalers by the way they marshal,
+	// unless the type map maps them, or they are enums or sql valuers. This
+	// is the default.
+	MarshalersAfterTypeMap MarshalerPrecedence = iota
+	// MarshalersBeforeTypeMap reflects marshalers by the way they marshal,
+	// even if the type map maps them, such as time.Time.
+	MarshalersBeforeTypeMap
+	// IgnoreMarshalers reflects marshalers the same as any other type.
+	IgnoreMarshalers
+)
+
+// WithMarshalers sets the precedence of marshalers, the go types that marshal
+// themselves. The default is MarshalersAfterTypeMap.
+func WithMarshalers(precedence MarshalerPrecedence) Option {
+	return func(o *options) {
+		o.marshalers = precedence
+	}
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	urlType             = reflect.TypeOf(url.URL{})
+)
+
+// marshaling is the way the values of a go type marshal themselves
+type marshaling int
+
+const (
+	notMarshaled marshaling = iota
+	textMarshaled
+	jsonMarshaled
+	stringMarshaled
+)
+
+// Get the way values of type t marshal themselves, as they are reflected by
+// the options, notMarshaled if they are reflected as any other type
+func (o *options) marshalingOf(t reflect.Type) marshaling {
+	if !o.marshalerPrecedes(t) {
+		return notMarshaled
+	}
+	switch {
+	case implements(t, textMarshalerType):
+		return textMarshaled
+	case t.Kind() == reflect.Struct && hasExportedFields(t):
+		// Reflected as objects, unless they marshal into text
+		return notMarshaled
+	case !isLeafKind(t.Kind()) && implements(t, jsonMarshalerType):
+		return jsonMarshaled
+	case implements(t, stringerType) &&
+		(t.Kind() == reflect.Array || t.Kind() == reflect.Struct):
+		return stringMarshaled
+	}
+	return notMarshaled
+}
+
+// Get the way input values of type t are unmarshaled, as they are reflected
+// by the options, notMarshaled if they are decoded as any other type
+func (o *options) unmarshalingOf(t reflect.Type) marshaling {
+	if !o.marshalerPrecedes(t) {
+		return notMarshaled
+	}
+	switch {
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return textMarshaled
+	case isScanner(t) || (t.Kind() == reflect.Struct && hasExportedFields(t)):
+		return notMarshaled
+	case !isLeafKind(t.Kind()) && reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		return jsonMarshaled
+	}
+	return notMarshaled
+}
+
+// Whether the type t may be reflected by the way it marshals, rather than by
+// the type map, as an enum or as an sql valuer
+func (o *options) marshalerPrecedes(t reflect.Type) bool {
+	switch {
+	case o.marshalers == IgnoreMarshalers:
+		return false
+	case t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface:
+		// Reflected by the type they hold
+		return false
+	case t.Kind() == reflect.Struct && o.hasTaggedFields(t):
+		// Reflected as objects, whatever the options
+		return false
+	case o.marshalers == MarshalersBeforeTypeMap:
+		return true
+	}
+	if _, exists := o.typeMap[t]; exists {
+		return false
+	}
+	_, isEnum := enumValues(t)
+	// Valuers that marshal into text, such as UUIDs, are reflected as text
+	return !isEnum && (!isValuer(t) || implements(t, textMarshalerType))
+}
+
+// Whether the struct type t has exported fields of its own, or promoted from
+// the structs it embeds, regardless of whether they are tagged or excluded
+func hasExportedFields(t reflect.Type) bool {
+	return len(structFields(t, fieldNaming{goNames: true})) > 0
+}
+
+// Whether the struct type t has fields tagged by the tag key of the options,
+// of its own or promoted from the structs it embeds
+func (o *options) hasTaggedFields(t reflect.Type) bool {
+	return len(structFields(t, fieldNaming{tagKey: o.naming.tagKey})) > 0
+}
+
+// Resolves url.URL values, which have exported fields but don't marshal into
+// text, to their String method
+func urlResolver(p graphql.ResolveParams) (interface{}, error) {
+	value := GetValueFromResolveParams(p)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	return stringMarshaled.convert(value)
+}
+
+// Whether t or a pointer to t implements the interface type iface
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// Whether values of the kind k are reflected as scalars by default
+func isLeafKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+		return false
+	}
+	return true
+}
+
+// The graphql scalar of the values marshaled this way
+func (m marshaling) scalar() *graphql.Scalar {
+	if m == jsonMarshaled {
+		return JSON
+	}
+	return graphql.String
+}
+
+// Convert the value v into the value it marshals into
+func (m marshaling) convert(v reflect.Value) (interface{}, error) {
+	v = indirectValue(v)
+	if !v.IsValid() || v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return nil, nil
+	}
+	var iface reflect.Type
+	switch m {
+	case textMarshaled:
+		iface = textMarshalerType
+	case jsonMarshaled:
+		iface = jsonMarshalerType
+	default:
+		iface = stringerType
+	}
+	if !v.Type().Implements(iface) {
+		if !reflect.PtrTo(v.Type()).Implements(iface) {
+			// Such as a string held by a map source
+			return valueInterface(v), nil
+		}
+		v = addressable(v).Addr()
+	}
+	switch marshaler := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	case json.Marshaler:
+		encoded, err := marshaler.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(encoded, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+	return v.Interface().(fmt.Stringer).String(), nil
+}
+
+// Get v if it's addressable, otherwise an addressable copy of it, so the
+// methods of its pointer may be called
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr.Elem()
+}
+
+// Decode the graphql input value src into dst by unmarshaling it
+func (m marshaling) decode(path string, src interface{}, dst reflect.Value) error {
+	var err error
+	switch m {
+	case textMarshaled:
+		s, ok := src.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode argument %q: cannot decode %T into %s",
+				path, src, dst.Type())
+		}
+		err = dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	case jsonMarshaled:
+		var encoded []byte
+		if encoded, err = json.Marshal(src); err == nil {
+			err = dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(encoded)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode argument %q: %s", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// MethodField exposes a method of a struct type as a computed field of the
+// graphql object reflected from the struct type. See MethodFielder.
+type MethodField struct {
+	// Method is the name of the go method
+	Method GoName
+	// Name is the name of the graphql field. The lower camel case of the
+	// method name is used if empty.
+	Name GqlName
+	// Args are the names of the graphql arguments of the positional
+	// parameters of the method, if it takes these rather than an args struct
+	Args []GqlName
+	// Description is the description of the graphql field, if any
+	Description string
+	// DeprecationReason is the reason the graphql field is deprecated, if
+	// it is
+	DeprecationReason string
+}
+
+// MethodFielder is implemented by struct types that expose some of their
+// methods as computed graphql fields, in addition to their struct fields.
+// GqlMethodFields is called on the zero value of the struct type.
+// Each method may take a context.Context, followed by either an args struct
+// or the positional parameters named by the MethodField, and must return a
+// result, optionally followed by an error. For example
+//
+//	func (u User) FullName() string
+//	func (u User) Total(currency string) (float64, error)
+//
+//	func (u User) GqlMethodFields() []reflector.MethodField {
+//		return []reflector.MethodField{
+//			{Method: "FullName"},
+//			{Method: "Total", Args: []reflector.GqlName{"currency"}},
+//		}
+//	}
+type MethodFielder interface {
+	GqlMethodFields() []MethodField
+}
+
+var methodFielderType = reflect.TypeOf((*MethodFielder)(nil)).Elem()
+
+// Reflect the method fields of the struct type t, which is named parent in
+// graphql, into fields
+func (r *reflection) reflectMethodFields(
+	parent GqlName,
+	t reflect.Type,
+	fields graphql.Fields,
+) {
+	ptrType := reflect.PtrTo(t)
+	if !ptrType.Implements(methodFielderType) {
+		return
+	}
+	methodFields := reflect.New(t).Interface().(MethodFielder).GqlMethodFields()
+	for _, mf := range methodFields {
+		m, exists := ptrType.MethodByName(string(mf.Method))
+		if !exists {
+			r.fail(t, "No method %s of %s", mf.Method, t)
+			continue
+		}
+		name := mf.Name
+		if name == "" {
+			name = r.naming.casing.method(m.Name)
+		}
+		if _, exists := fields[string(name)]; exists {
+			r.fail(t, "Method %s of %s and another field are both named %s",
+				m.Name, t, name)
+			continue
+		}
+		// The signature of the method, without the receiver
+		sig, err := parseFuncSignature(reflect.New(t).Method(m.Index).Type(), len(mf.Args))
+		if err != nil {
+			r.fail(t, "Cannot reflect method %s of %s: %s", m.Name, t, err)
+			continue
+		}
+		method := m.Func
+		pop := r.at("." + m.Name + "()")
+		field := r.reflectCall(name, parent+"_"+name, sig, mf.Args,
+			func(p graphql.ResolveParams, in []reflect.Value) ([]reflect.Value, error) {
+				receiver, err := methodReceiver(p.Source, t)
+				if err != nil {
+					return nil, err
+				}
+				return method.Call(append([]reflect.Value{receiver}, in...)), nil
+			})
+		pop()
+		field.Description = mf.Description
+		field.DeprecationReason = mf.DeprecationReason
+		fields[string(name)] = field
+	}
+}
+
+// Get a pointer to the struct of type t held by source, so that methods of
+// both t and *t may be called on it
+func methodReceiver(source interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(source)
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		if v.Type() == reflect.PtrTo(t) {
+			return v, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != t {
+		return reflect.Value{}, fmt.Errorf("cannot call a method of %s on %T", t, source)
+	}
+	// Copy v, since it may not be addressable
+	ptr := reflect.New(t)
+	ptr.Elem().Set(v)
+	return ptr, nil
+}
@@ -0,0 +1,157 @@
+package reflector
+
+import (
+	"reflect"
+	"strings"
+)
+
+// TypeNamer names the graphql type reflected from the go type t.
+// name is the name the reflection would give t if it has no name of its own,
+// such as for anonymous structs. It's either the name given to ReflectTypeFq,
+// or derived from the path of t within its parent types, for example
+// `Order_items`.
+type TypeNamer func(t reflect.Type, name GqlName) GqlName
+
+// GoTypeNamer is the default TypeNamer. It names types after their go name,
+// without the package, and falls back to the given name for unnamed types.
+// Characters that are not allowed in graphql names, such as the brackets of
+// generic types, are replaced by underscores.
+func GoTypeNamer(t reflect.Type, name GqlName) GqlName {
+	if t.Name() != "" {
+		return sanitizeName(t.Name())
+	}
+	return sanitizeName(string(name))
+}
+
+// Get the name of the graphql type reflected from t, and make sure no other
+// go type has the same name.
+func (r *reflection) typeName(t reflect.Type, name GqlName) GqlName {
+	return r.registerTypeName(t, r.namer(t, name))
+}
+
+// Register n as the graphql name of the go type t.
+// Named types of different packages that share the same name, are qualified
+// by the package path, except for the one whose package path is the smallest,
+// among the types reachable from the reflected type, which keeps the name.
+// This way the names don't depend on the order the types are reflected in,
+// unless they are reflected separately into the same cache.
+func (r *reflection) registerTypeName(t reflect.Type, n GqlName) GqlName {
+	if t.Name() != "" {
+		claim, claimed := r.claims[t.Name()]
+		other, exists := r.cache.names[n]
+		if (claimed && claim.PkgPath() != t.PkgPath()) ||
+			(exists && other != t && other.Name() != "" && t.PkgPath() != other.PkgPath()) {
+			n = sanitizeName(t.PkgPath()) + "_" + n
+		}
+	}
+	other, exists := r.cache.names[n]
+	if exists && other != t {
+		if other.String() == t.String() {
+			// Such as types declared in different functions
+			r.fail(t, "GQL type name collision: two different types %s are named %s", t, n)
+		} else {
+			r.fail(t, "GQL type name collision: both %s and %s are named %s",
+				other, t, n)
+		}
+		return n
+	}
+	if !exists {
+		previous, named := r.cache.names[n]
+		r.cache.names[n] = t
+		r.added(t, func() {
+			if named {
+				r.cache.names[n] = previous
+			} else {
+				delete(r.cache.names, n)
+			}
+		})
+	}
+	return n
+}
+
+// Claim the names of the named types reachable from t that may be reflected
+// as named graphql types, before any of them is named. Of the types of
+// different packages that share a go name, the one whose package path is the
+// smallest claims the name.
+func (r *reflection) claimNames(t reflect.Type) {
+	if r.claimed[t] {
+		return
+	}
+	if r.claimed == nil {
+		r.claimed = make(map[reflect.Type]bool)
+		r.claims = make(map[string]reflect.Type)
+	}
+	r.claimed[t] = true
+	if _, exists := r.typeMap[t]; exists || r.marshalingOf(t) != notMarshaled || isValuer(t) {
+		return
+	}
+	// Whether t is reflected as a graphql type named after it
+	_, named := enumValues(t)
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface, reflect.Map:
+		named = true
+	}
+	if claim, exists := r.claims[t.Name()]; named && t.Name() != "" && t.PkgPath() != "" &&
+		(!exists || t.PkgPath() < claim.PkgPath()) {
+		r.claims[t.Name()] = t
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		r.claimNames(t.Elem())
+	case reflect.Map:
+		r.claimNames(t.Key())
+		r.claimNames(t.Elem())
+	case reflect.Struct:
+		for _, f := range structFields(t, r.naming) {
+			r.claimNames(f.Type)
+		}
+		if ptrType := reflect.PtrTo(t); ptrType.Implements(methodFielderType) {
+			for _, mf := range reflect.New(t).Interface().(MethodFielder).GqlMethodFields() {
+				if m, exists := ptrType.MethodByName(string(mf.Method)); exists {
+					r.claimNames(m.Type)
+				}
+			}
+		}
+	case reflect.Interface:
+		for _, impl := range r.polymorphic[t].impls {
+			r.claimNames(impl)
+		}
+	case reflect.Func:
+		for i := 0; i < t.NumIn(); i++ {
+			r.claimNames(t.In(i))
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			r.claimNames(t.Out(i))
+		}
+	}
+}
+
+// Make s a valid graphql name, matching /[_A-Za-z][_0-9A-Za-z]*/
+// Package paths within type parameters are dropped, for example
+// `Pair[int,github.com/x/y.Z]` becomes `Pair_int_y_Z`
+func sanitizeName(s string) GqlName {
+	var parts []string
+	for _, part := range strings.FieldsFunc(s, func(c rune) bool {
+		return !(c == '.' || c == '/' || c == '_' || isAlphaNumeric(c))
+	}) {
+		if i := strings.LastIndex(part, "/"); i >= 0 && strings.ContainsRune(s, '[') {
+			part = part[i+1:]
+		}
+		part = strings.Map(func(c rune) rune {
+			if c == '_' || isAlphaNumeric(c) {
+				return c
+			}
+			return '_'
+		}, part)
+		parts = append(parts, part)
+	}
+	name := strings.Join(parts, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return GqlName(name)
+}
+
+func isAlphaNumeric(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
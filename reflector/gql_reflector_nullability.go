@@ -0,0 +1,129 @@
+package reflector
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// NullabilityPolicy defines which struct fields are reflected as non-null
+// graphql fields, when the field does not say so explicitly by a gqlnull tag
+type NullabilityPolicy int
+
+const (
+	// NullableFields reflects all fields as nullable graphql fields.
+	NullableFields NullabilityPolicy = iota
+	// InferNonNull reflects fields that can never be null as non-null graphql
+	// fields. These are all fields that are not pointers, interfaces, maps
+	// etc and are not tagged with omitempty. The same goes for the
+	// elements of lists.
+	InferNonNull
+)
+
+// Values of the gqlnull struct field tag. Multiple values may be used in a
+// coma separated list, for example `gqlnull:"required,elem_nullable"`
+const (
+	// GqlNullRequired makes the field non-null
+	GqlNullRequired = "required"
+	// GqlNullNullable makes the field nullable
+	GqlNullNullable = "nullable"
+	// GqlNullElemRequired makes the elements of a list field non-null
+	GqlNullElemRequired = "elem_required"
+	// GqlNullElemNullable makes the elements of a list field nullable
+	GqlNullElemNullable = "elem_nullable"
+)
+
+// Wrap gqlType, the type of the struct field f, by graphql.NonNull according
+// to the nullability policy and the gqlnull tag of f.
+// gqlType may be either an output or an input type.
+func (r *reflection) applyNullability(
+	f structField,
+	gqlType graphql.Type,
+) graphql.Type {
+	required := r.nullability == InferNonNull &&
+		!canBeNil(f.Type) && !f.viaPointer && !f.omitEmpty &&
+		!(r.zeroTimeAsNull && f.Type == timeType)
+	elemRequired := r.nullability == InferNonNull
+	if t := indirectType(f.Type); t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		elemRequired = elemRequired && !canBeNil(t.Elem())
+	}
+	for _, s := range strings.Split(f.Tag.Get(GqlNullTagName), ",") {
+		switch strings.Trim(s, " ") {
+		case GqlNullRequired:
+			required = true
+		case GqlNullNullable:
+			required = false
+		case GqlNullElemRequired:
+			elemRequired = true
+		case GqlNullElemNullable:
+			elemRequired = false
+		}
+	}
+
+	if list, ok := gqlType.(*graphql.List); ok && elemRequired {
+		gqlType = graphql.NewList(nonNull(list.OfType))
+	}
+	if required {
+		gqlType = nonNull(gqlType)
+	}
+	return gqlType
+}
+
+func nonNull(t graphql.Type) *graphql.NonNull {
+	if nn, ok := t.(*graphql.NonNull); ok {
+		return nn
+	}
+	return graphql.NewNonNull(t)
+}
+
+// Get the resolver of a field of the go type t, whose graphql type is gqlType,
+// that resolves nil slices and maps to empty lists if gqlType is a non-null
+// list, since it may not resolve to null, rather than as resolve does
+func emptyListResolver(
+	t reflect.Type,
+	gqlType graphql.Type,
+	resolve graphql.FieldResolveFn,
+) graphql.FieldResolveFn {
+	nn, isNonNull := gqlType.(*graphql.NonNull)
+	if !isNonNull || (t.Kind() != reflect.Slice && t.Kind() != reflect.Map) || resolve == nil {
+		return resolve
+	}
+	if _, isList := nn.OfType.(*graphql.List); !isList {
+		return resolve
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := resolve(p)
+		if err != nil {
+			return nil, err
+		}
+		if v := reflect.ValueOf(result); !v.IsValid() || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return []interface{}{}, nil
+		}
+		return result, nil
+	}
+}
+
+// Whether a value of type t may be nil, and hence resolved to null
+func canBeNil(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Func,
+		reflect.Chan, reflect.UnsafePointer:
+		return true
+	case reflect.Struct, reflect.Array:
+		// Such as sql.NullString
+		return isNullValuer(t)
+	}
+	return false
+}
+
+// Whether the tag of the struct field f has the given option, that is any
+// value besides the first one of the coma separated tag
+func hasTagOption(f reflect.StructField, tag string, option string) bool {
+	for _, s := range strings.Split(f.Tag.Get(tag), ",")[1:] {
+		if strings.Trim(s, " ") == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,415 @@
+package reflector
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Values of the gqlint struct field tag, which sets the scalar of an integer
+// field, or of the elements of a list field, for example `gqlint:"int64"`
+const (
+	// GqlIntInt reflects an integer field as an Int, which is 32 bits
+	GqlIntInt = "int"
+	// GqlIntInt64 reflects an integer field as an Int64
+	GqlIntInt64 = "int64"
+	// GqlIntUInt64 reflects an integer field as a UInt64
+	GqlIntUInt64 = "uint64"
+)
+
+// Int64 is a graphql scalar of 64 bit signed integers, which are encoded as
+// strings, since Int is only 32 bits. Input values may also be given as
+// numbers.
+var Int64 = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Int64",
+	Description: "The `Int64` scalar type represents a signed 64 bit integer, " +
+		"encoded as a string, such as `\"-9223372036854775808\"`.",
+	Serialize: serializeInteger,
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil
+			}
+			return i
+		}
+		if i, ok := toInt64(reflect.ValueOf(value)); ok {
+			return i
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue, *ast.IntValue:
+			i, err := strconv.ParseInt(valueAST.GetValue().(string), 10, 64)
+			if err != nil {
+				return nil
+			}
+			return i
+		}
+		return nil
+	},
+})
+
+// UInt64 is a graphql scalar of 64 bit unsigned integers, which are encoded as
+// strings. Input values may also be given as numbers.
+var UInt64 = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "UInt64",
+	Description: "The `UInt64` scalar type represents an unsigned 64 bit integer, " +
+		"encoded as a string, such as `\"18446744073709551615\"`.",
+	Serialize: serializeInteger,
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			u, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil
+			}
+			return u
+		}
+		if u, ok := toUint64(reflect.ValueOf(value)); ok {
+			return u
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue, *ast.IntValue:
+			u, err := strconv.ParseUint(valueAST.GetValue().(string), 10, 64)
+			if err != nil {
+				return nil
+			}
+			return u
+		}
+		return nil
+	},
+})
+
+// BigInt is a graphql scalar of big.Int values, encoded as decimal strings
+var BigInt = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "BigInt",
+	Description: "The `BigInt` scalar type represents an integer of any size, " +
+		"encoded as a decimal string.",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case big.Int:
+			return value.String()
+		case *big.Int:
+			if value == nil {
+				return nil
+			}
+			return value.String()
+		}
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case *big.Int:
+			return value
+		case string:
+			if i, ok := new(big.Int).SetString(value, 10); ok {
+				return i
+			}
+			return nil
+		}
+		if i, ok := toInt64(reflect.ValueOf(value)); ok {
+			return big.NewInt(i)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue, *ast.IntValue:
+			if i, ok := new(big.Int).SetString(valueAST.GetValue().(string), 10); ok {
+				return i
+			}
+		}
+		return nil
+	},
+})
+
+// BigFloat is a graphql scalar of big.Float values, encoded as strings in the
+// shortest decimal form that represents them exactly
+var BigFloat = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "BigFloat",
+	Description: "The `BigFloat` scalar type represents a floating point number of " +
+		"any precision, encoded as a decimal string.",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case big.Float:
+			return value.Text('g', -1)
+		case *big.Float:
+			if value == nil {
+				return nil
+			}
+			return value.Text('g', -1)
+		}
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case *big.Float:
+			return value
+		case string:
+			if f, ok := new(big.Float).SetString(value); ok {
+				return f
+			}
+			return nil
+		case float32:
+			return big.NewFloat(float64(value))
+		case float64:
+			return big.NewFloat(value)
+		}
+		if i, ok := toInt64(reflect.ValueOf(value)); ok {
+			return new(big.Float).SetInt64(i)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue, *ast.IntValue, *ast.FloatValue:
+			if f, ok := new(big.Float).SetString(valueAST.GetValue().(string)); ok {
+				return f
+			}
+		}
+		return nil
+	},
+})
+
+// BigRat is a graphql scalar of big.Rat values, encoded as fractions, such as
+// 1/3, or as integers when their denominator is 1. Input values may also be
+// given as decimal numbers.
+var BigRat = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "BigRat",
+	Description: "The `BigRat` scalar type represents a rational number, " +
+		"encoded as a fraction, such as `\"1/3\"`, or as an integer.",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case big.Rat:
+			return value.RatString()
+		case *big.Rat:
+			if value == nil {
+				return nil
+			}
+			return value.RatString()
+		}
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case *big.Rat:
+			return value
+		case string:
+			if r, ok := new(big.Rat).SetString(value); ok {
+				return r
+			}
+			return nil
+		case float32:
+			return new(big.Rat).SetFloat64(float64(value))
+		case float64:
+			return new(big.Rat).SetFloat64(value)
+		}
+		if i, ok := toInt64(reflect.ValueOf(value)); ok {
+			return new(big.Rat).SetInt64(i)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue, *ast.IntValue, *ast.FloatValue:
+			if r, ok := new(big.Rat).SetString(valueAST.GetValue().(string)); ok {
+				return r
+			}
+		}
+		return nil
+	},
+})
+
+// The scalars of the math/big types, which are decoded by parsing the input
+// value by the scalar
+var bigScalars = map[reflect.Type]*graphql.Scalar{
+	reflect.TypeOf(big.Int{}):   BigInt,
+	reflect.TypeOf(big.Float{}): BigFloat,
+	reflect.TypeOf(big.Rat{}):   BigRat,
+}
+
+// The mappings of the math/big types to their scalars
+func bigTypeMap() TypeMap {
+	typeMap := make(TypeMap, len(bigScalars))
+	for t, scalar := range bigScalars {
+		typeMap[t] = GqlOutputAndResolver{
+			Output:   scalar,
+			Resolver: trivialResolver,
+		}
+	}
+	return typeMap
+}
+
+// Int64TypeMap returns the mappings of the go integer types that may not fit
+// in an Int, which is 32 bits, to the Int64 and UInt64 scalars. Layer it over
+// the type map in order to reflect all of these types as 64 bit scalars:
+//
+//	gqlt := ReflectType(A{}, WithTypeMapLayer(Int64TypeMap()))
+func Int64TypeMap() TypeMap {
+	return TypeMap{
+		reflect.TypeOf(int(0)): {
+			Output:   Int64,
+			Resolver: trivialResolver,
+		},
+		reflect.TypeOf(int64(0)): {
+			Output:   Int64,
+			Resolver: trivialResolver,
+		},
+		reflect.TypeOf(uint(0)): {
+			Output:   UInt64,
+			Resolver: trivialResolver,
+		},
+		reflect.TypeOf(uint32(0)): {
+			Output:   UInt64,
+			Resolver: trivialResolver,
+		},
+		reflect.TypeOf(uint64(0)): {
+			Output:   UInt64,
+			Resolver: trivialResolver,
+		},
+	}
+}
+
+// Encode value, an integer or a pointer to one, as a decimal string.
+// Strings are taken as already encoded, and anything else is invalid.
+func serializeInteger(value interface{}) interface{} {
+	v := indirectValue(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.String:
+		return v.String()
+	}
+	return nil
+}
+
+// Get the scalar of the integer field f by its gqlint tag, nil if it has none
+func (r *reflection) intFieldScalar(f structField) *graphql.Scalar {
+	switch tag := f.Tag.Get(GqlIntTagName); tag {
+	case "":
+		return nil
+	case GqlIntInt:
+		return graphql.Int
+	case GqlIntInt64:
+		return Int64
+	case GqlIntUInt64:
+		return UInt64
+	default:
+		r.fail(f.Type, "Invalid %s tag %q of %s, expected %s, %s or %s",
+			GqlIntTagName, tag, f.Name, GqlIntInt, GqlIntInt64, GqlIntUInt64)
+		return graphql.Int
+	}
+}
+
+// Reflect the integer type t, or a list or pointer type of integers, as the
+// scalar, such as [Int64] for []int64
+func (r *reflection) reflectIntType(t reflect.Type, scalar *graphql.Scalar) graphql.Type {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return r.reflectIntType(t.Elem(), scalar)
+	case reflect.Slice, reflect.Array:
+		defer r.at("[]")()
+		return graphql.NewList(r.reflectIntType(t.Elem(), scalar))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.checkIntFits(t, scalar)
+		return scalar
+	}
+	r.fail(t, "The %s tag only applies to integers and lists of integers, not to %s",
+		GqlIntTagName, t)
+	return scalar
+}
+
+// Check that any value of the integer type t fits in the scalar, when integers
+// are strict (see WithStrictIntegers)
+func (r *reflection) checkIntFits(t reflect.Type, scalar graphql.Type) {
+	if r.strictIntegers && !intFits(t, scalar) {
+		r.fail(t, "%s does not fit in %s, use a gqlint tag or Int64TypeMap", t, scalar)
+	}
+}
+
+// Whether any value of the type t fits in the scalar. Only integer types may
+// not fit, in the integer scalars.
+func intFits(t reflect.Type, scalar graphql.Type) bool {
+	var signed bool
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		signed = true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		signed = false
+	default:
+		return true
+	}
+	switch scalar {
+	case graphql.Int:
+		return (signed && t.Bits() <= 32) || (!signed && t.Bits() < 32)
+	case Int64:
+		return signed || t.Bits() < 64
+	case UInt64:
+		return !signed
+	}
+	return true
+}
+
+// Wrap resolve, the resolver of a field of the go type t, whose graphql type
+// is gqlType, so that integers that don't fit in an Int fail to resolve,
+// rather than resolve to null, as graphql serializes them. Lists of integers
+// fail as a whole.
+func intRangeResolver(
+	t reflect.Type,
+	gqlType graphql.Type,
+	resolve graphql.FieldResolveFn,
+) graphql.FieldResolveFn {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if resolve == nil || graphql.GetNamed(gqlType) != graphql.Int || intFits(t, graphql.Int) {
+		return resolve
+	}
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := resolve(p)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIntRange(reflect.ValueOf(result)); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// Check that the integer v, or the integers of the list v, fit in an Int
+func checkIntRange(v reflect.Value) error {
+	v = indirectValue(v)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); i < math.MinInt32 || i > math.MaxInt32 {
+			return fmt.Errorf("%d does not fit in Int, use a gqlint tag or Int64TypeMap", i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		if u := v.Uint(); u > math.MaxInt32 {
+			return fmt.Errorf("%d does not fit in Int, use a gqlint tag or Int64TypeMap", u)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkIntRange(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package reflector
+
+import "reflect"
+
+// Option configures the way go types are reflected into graphql types.
+// Options are passed as the last arguments of the Reflect* functions.
+type Option func(*options)
+
+// options holds the configurable knobs of a reflection
+type options struct {
+	typeMap TypeMap
+	// The type maps layered over typeMap, see WithTypeMapLayer
+	layers      []TypeMap
+	exclude     ExcludeFieldTag
+	nullability NullabilityPolicy
+	namer       TypeNamer
+	cache       *TypeCache
+	inputSuffix string
+	naming      fieldNaming
+	// Whether omitempty fields resolve to null when their value is empty
+	omitEmptyAsNull bool
+	// Whether zero time fields resolve to null
+	zeroTimeAsNull bool
+	// Whether time fields take a format argument
+	timeFormatArg bool
+	// The precedence of go types that marshal themselves
+	marshalers MarshalerPrecedence
+	// Whether integer types must fit in the scalars they are reflected as
+	strictIntegers bool
+	// The go interface types to reflect as graphql interfaces or unions
+	polymorphic map[reflect.Type]polymorphic
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		typeMap:     defaultTypeMap,
+		nullability: NullableFields,
+		namer:       GoTypeNamer,
+		inputSuffix: DefaultInputSuffix,
+		naming:      defaultFieldNaming,
+		polymorphic: make(map[reflect.Type]polymorphic),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTypeMap sets the type map by which go types are reflected into graphql
+// types before reflecting them by their kind. The default is the map returned
+// by GetDefaultTypeMap.
+func WithTypeMap(typeMap TypeMap) Option {
+	return func(o *options) {
+		o.typeMap = typeMap
+	}
+}
+
+// WithTypeMapLayer layers typeMap over the type map: the go types that
+// typeMap maps are looked up in it first, and all others in the type map that
+// is set by WithTypeMap or passed to a Reflect*Fq function. Later layers take
+// precedence over earlier ones.
+func WithTypeMapLayer(typeMap TypeMap) Option {
+	return func(o *options) {
+		o.layers = append(o.layers, typeMap)
+	}
+}
+
+// WithExclude sets the exclude tag: struct fields whose gqlexclude tag lists
+// it are left out. By default no field is left out.
+func WithExclude(exclude ExcludeFieldTag) Option {
+	return func(o *options) {
+		o.exclude = exclude
+	}
+}
+
+// WithNullability sets the policy by which struct fields are reflected as
+// nullable or non-null graphql fields. The default is NullableFields.
+func WithNullability(policy NullabilityPolicy) Option {
+	return func(o *options) {
+		o.nullability = policy
+	}
+}
+
+// WithTypeNamer sets the way graphql types are named after the go types they
+// are reflected from. The default is GoTypeNamer.
+func WithTypeNamer(namer TypeNamer) Option {
+	return func(o *options) {
+		o.namer = namer
+	}
+}
+
+// WithTypeCache makes the reflection reuse the types reflected by previous
+// reflections that used the same cache, and add its own types to the cache.
+func WithTypeCache(cache *TypeCache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithInputSuffix sets the suffix added to the names of reflected input
+// objects. The default is DefaultInputSuffix.
+func WithInputSuffix(suffix string) Option {
+	return func(o *options) {
+		o.inputSuffix = suffix
+	}
+}
+
+// WithTagKey sets the key of the struct field tag that names graphql fields,
+// for example "gql" for `gql:"name"`. The default is DefaultTagKey.
+// The same tag key must be passed to DecodeArgs.
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.naming.tagKey = key
+	}
+}
+
+// WithUntaggedFields sets whether exported struct fields that are not named
+// by a tag are named by their go names, rather than left out, which is the
+// default.
+func WithUntaggedFields(include bool) Option {
+	return func(o *options) {
+		o.naming.goNames = include
+	}
+}
+
+// WithFieldCasing sets the casing strategy of field names, including the
+// names of method fields. The default is KeepCase, which names methods in
+// lower camel case.
+func WithFieldCasing(casing FieldCasing) Option {
+	return func(o *options) {
+		o.naming.casing = casing
+	}
+}
+
+// WithOmitEmptyAsNull sets whether fields whose tag has the omitempty option
+// resolve to null when their value is empty, the same as encoding/json omits
+// them: false, 0, "", nil and empty lists and maps. By default they resolve to
+// their value.
+func WithOmitEmptyAsNull(enable bool) Option {
+	return func(o *options) {
+		o.omitEmptyAsNull = enable
+	}
+}
+
+// WithZeroTimeAsNull sets whether time.Time fields resolve to null when their
+// value is the zero time. By default they resolve to their value.
+func WithZeroTimeAsNull(enable bool) Option {
+	return func(o *options) {
+		o.zeroTimeAsNull = enable
+	}
+}
+
+// WithTimeFormatArg sets whether DateTime fields take a format argument of
+// type TimeFormat, by which clients choose the format of the time. By default
+// they take no arguments.
+func WithTimeFormatArg(enable bool) Option {
+	return func(o *options) {
+		o.timeFormatArg = enable
+	}
+}
+
+// WithStrictIntegers sets whether reflecting an integer type as a scalar that
+// not all of its values fit in fails, for example int64 as an Int, which is 32
+// bits. By default only values that don't fit fail to resolve, with an error.
+func WithStrictIntegers(enable bool) Option {
+	return func(o *options) {
+		o.strictIntegers = enable
+	}
+}
@@ -0,0 +1,211 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// polymorphic describes how a go interface type is reflected, as registered
+// by WithInterface or WithUnion
+type polymorphic struct {
+	union bool
+	// The struct type that declares the fields of an interface, nil if the
+	// fields are the methods of the go interface
+	fields reflect.Type
+	// The struct types that implement the go interface
+	impls []reflect.Type
+}
+
+// WithInterface reflects the go interface type iface as a graphql interface,
+// implemented by the objects reflected from the given implementations.
+// iface is given by a nil pointer to it, for example (*Payment)(nil).
+// The fields of the graphql interface are those of the fields struct, if it's
+// not nil, and each of the implementations must have them. Otherwise, the
+// fields are the methods of the go interface that can be reflected as
+// functions (see ReflectFuncFq), and they're added to the implementations.
+// Since the implementations may not be reachable otherwise, add
+// TypeCache.Types to the types of the schema.
+func WithInterface(iface interface{}, fields interface{}, impls ...interface{}) Option {
+	p := polymorphic{impls: implTypes(impls)}
+	if fields != nil {
+		p.fields = indirectType(reflect.TypeOf(fields))
+	}
+	return withPolymorphic(iface, p)
+}
+
+// WithUnion reflects the go interface type iface as a graphql union of the
+// objects reflected from the given implementations.
+// iface is given by a nil pointer to it, for example (*SearchResult)(nil).
+func WithUnion(iface interface{}, impls ...interface{}) Option {
+	return withPolymorphic(iface, polymorphic{
+		union: true,
+		impls: implTypes(impls),
+	})
+}
+
+func withPolymorphic(iface interface{}, p polymorphic) Option {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("Cannot reflect %T as a graphql interface or union, "+
+			"a nil pointer to an interface is needed", iface))
+	}
+	for _, impl := range p.impls {
+		if !impl.Implements(t.Elem()) && !reflect.PtrTo(impl).Implements(t.Elem()) {
+			panic(fmt.Sprintf("%s does not implement %s", impl, t.Elem()))
+		}
+	}
+	return func(o *options) {
+		o.polymorphic[t.Elem()] = p
+	}
+}
+
+func implTypes(impls []interface{}) []reflect.Type {
+	types := make([]reflect.Type, len(impls))
+	for i, impl := range impls {
+		types[i] = indirectType(reflect.TypeOf(impl))
+		if types[i].Kind() != reflect.Struct {
+			panic(fmt.Sprintf("Implementation %T is not a struct", impl))
+		}
+	}
+	return types
+}
+
+// Reflect the go interface type t as a graphql interface or union, if it's
+// registered as one, nil otherwise. name is the name to use for t if it has
+// no name of its own.
+func (r *reflection) reflectPolymorphic(name GqlName, t reflect.Type) graphql.Output {
+	if abstract, exists := r.cache.abstracts[t]; exists {
+		return abstract
+	}
+	p, exists := r.polymorphic[t]
+	if !exists {
+		return nil
+	}
+
+	name = r.typeName(t, name)
+	// Filled once the implementations are reflected, before any type is
+	// resolved
+	objects := make(map[reflect.Type]*graphql.Object)
+	resolveType := func(params graphql.ResolveTypeParams) *graphql.Object {
+		return objects[indirectType(reflect.TypeOf(params.Value))]
+	}
+
+	var abstract graphql.Output
+	if p.union {
+		types := make([]*graphql.Object, len(p.impls))
+		abstract = graphql.NewUnion(graphql.UnionConfig{
+			Name:        string(name),
+			Types:       types,
+			ResolveType: resolveType,
+		})
+		r.cache.abstracts[t] = abstract
+		r.added(t, func() { delete(r.cache.abstracts, t) })
+		for i, impl := range p.impls {
+			types[i] = r.reflectObject(GqlName(impl.Name()), impl)
+			objects[impl] = types[i]
+		}
+		return abstract
+	}
+
+	var fields graphql.Fields
+	var description string
+	if p.fields != nil {
+		description = typeDescription(p.fields)
+	}
+	abstract = graphql.NewInterface(graphql.InterfaceConfig{
+		Name:        string(name),
+		Description: description,
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return fields
+		}),
+		ResolveType: resolveType,
+	})
+	r.cache.abstracts[t] = abstract
+	r.added(t, func() { delete(r.cache.abstracts, t) })
+	if p.fields != nil {
+		fields = r.reflectFields(name, p.fields)
+	} else {
+		fields = r.reflectInterfaceMethods(name, t, t)
+	}
+	for _, impl := range p.impls {
+		objects[impl] = r.reflectObject(GqlName(impl.Name()), impl)
+	}
+	return abstract
+}
+
+// Get the graphql interfaces the struct type t implements, adding the fields
+// of those whose fields are methods to fields
+func (r *reflection) reflectImplementedInterfaces(
+	parent GqlName,
+	t reflect.Type,
+	fields graphql.Fields,
+) []*graphql.Interface {
+	var interfaces []*graphql.Interface
+	for iface, p := range r.polymorphic {
+		if p.union || !containsType(p.impls, t) {
+			continue
+		}
+		interfaces = append(interfaces, r.reflectPolymorphic(GqlName(iface.Name()), iface).(*graphql.Interface))
+		if p.fields == nil {
+			for name, field := range r.reflectInterfaceMethods(parent, iface, t) {
+				if _, exists := fields[name]; !exists {
+					fields[name] = field
+				}
+			}
+		}
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Name() < interfaces[j].Name()
+	})
+	return interfaces
+}
+
+// Reflect the methods of the go interface iface that can be reflected as
+// functions, into fields that resolve by calling the method on a t, named
+// parent in graphql
+func (r *reflection) reflectInterfaceMethods(
+	parent GqlName,
+	iface reflect.Type,
+	t reflect.Type,
+) graphql.Fields {
+	fields := make(graphql.Fields)
+	for i := 0; i < iface.NumMethod(); i++ {
+		m := iface.Method(i)
+		sig, err := parseFuncSignature(m.Type, 0)
+		if err != nil {
+			// Not every method is meant to be a field
+			continue
+		}
+		name := r.naming.casing.method(m.Name)
+		receiverType := t
+		var method reflect.Value
+		if t.Kind() == reflect.Struct {
+			implMethod, _ := reflect.PtrTo(t).MethodByName(m.Name)
+			method = implMethod.Func
+		}
+		fields[string(name)] = r.reflectCall(name, parent+"_"+name, sig, nil,
+			func(p graphql.ResolveParams, in []reflect.Value) ([]reflect.Value, error) {
+				if !method.IsValid() {
+					return nil, fmt.Errorf("cannot call %s of the interface %s", m.Name, iface)
+				}
+				receiver, err := methodReceiver(p.Source, receiverType)
+				if err != nil {
+					return nil, err
+				}
+				return method.Call(append([]reflect.Value{receiver}, in...)), nil
+			})
+	}
+	return fields
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, other := range types {
+		if other == t {
+			return true
+		}
+	}
+	return false
+}
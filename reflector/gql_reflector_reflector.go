@@ -0,0 +1,196 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Reflector reflects go types into graphql types, configured once by its
+// options, such as WithTypeMap, WithExclude and WithNullability.
+// All the types reflected by a Reflector share its type cache (see
+// WithTypeCache), so they may be used in the same schema. A Reflector is safe
+// for concurrent use.
+// Rather than panic, its methods return all the problems found in the go
+// types as ReflectionErrors.
+// A Reflector holds its type cache while it reflects, so the functions it
+// calls meanwhile, such as its TypeNamer and the GqlMethodFields methods of
+// the reflected types, must not call the Reflector themselves.
+type Reflector struct {
+	options
+}
+
+// New returns a Reflector configured by opts. The type maps it's given are
+// copied, so changing the maps later on doesn't affect the Reflector.
+func New(opts ...Option) *Reflector {
+	o := newOptions(opts)
+	o.typeMap = o.typeMap.Override(o.layers...)
+	if o.cache == nil {
+		o.cache = NewTypeCache()
+	}
+	return &Reflector{options: o}
+}
+
+// Make the Reflector of the free functions, which take the type map and the
+// exclude tag as arguments, and reflect into the default cache of their
+// configuration unless given one (see TypeCache)
+func newReflector(typeMap TypeMap, exclude ExcludeFieldTag, opts []Option) *Reflector {
+	o := newOptions(opts)
+	o.typeMap = typeMap
+	if len(o.layers) > 0 {
+		o.typeMap = typeMap.Override(o.layers...)
+	}
+	o.exclude = exclude
+	if o.cache == nil {
+		o.cache = defaultCache(o)
+	}
+	return &Reflector{options: o}
+}
+
+// Start a reflection, which holds the type cache until done is called
+func (rf *Reflector) start() *reflection {
+	r := &reflection{options: rf.options}
+	if r.cache == nil {
+		r.cache = NewTypeCache()
+	}
+	r.cache.mu.Lock()
+	return r
+}
+
+// Type reflects the type of instance, the same way as ReflectType does.
+func (rf *Reflector) Type(instance interface{}) (graphql.Type, error) {
+	if instance == nil {
+		return nil, fmt.Errorf("cannot infer type of nil instance")
+	}
+	t := reflect.TypeOf(instance)
+	return rf.TypeFq(GqlName(indirectType(t).Name()), t)
+}
+
+// TypeFq reflects the go type t, the same way as ReflectTypeFq does.
+func (rf *Reflector) TypeFq(name GqlName, t reflect.Type) (graphql.Type, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(rootPath(name, t))
+	gqlType := r.reflectType(name, t)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return gqlType, nil
+}
+
+// Fields reflects the fields of the struct type t, the same way as
+// ReflectFieldsFq does.
+func (rf *Reflector) Fields(t reflect.Type) (graphql.Fields, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(rootPath("", t))
+	fields := r.reflectFields(r.namer(t, GqlName(t.Name())), t)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Field reflects a field of type t, the same way as ReflectFieldFq does.
+func (rf *Reflector) Field(name GqlName, t reflect.Type) (*graphql.Field, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(rootPath(name, t))
+	field := r.reflectField(name, t, name)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// InputType reflects the go type t as an input type, the same way as
+// ReflectInputTypeFq does.
+func (rf *Reflector) InputType(name GqlName, t reflect.Type) (graphql.Input, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(rootPath(name, t))
+	gqlType := r.reflectInputType(name, t)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return gqlType, nil
+}
+
+// Args reflects the fields of the struct type t as arguments, the same way
+// as ReflectArgsFq does.
+func (rf *Reflector) Args(t reflect.Type) (graphql.FieldConfigArgument, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(rootPath("", t))
+	args := r.reflectArgs(t)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// Func reflects a field that resolves by calling fn, the same way as
+// ReflectFuncFq does.
+func (rf *Reflector) Func(name GqlName, fn interface{}) (*graphql.Field, error) {
+	r := rf.start()
+	defer r.done()
+	r.collectErrors(string(name))
+	field := r.reflectFunc(name, reflect.ValueOf(fn))
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// Schema builds a graphql schema from the go values query and mutation, the
+// same way as BuildSchemaFq does. Every call builds a new schema, with root
+// objects of its own, that shares the other types with the previous schemas
+// of the Reflector.
+func (rf *Reflector) Schema(
+	query interface{},
+	mutation interface{},
+) (graphql.Schema, *SchemaReport, error) {
+	if query == nil {
+		return graphql.Schema{}, nil, fmt.Errorf("a query root value is required")
+	}
+	r := rf.start()
+	defer r.done()
+	report := &SchemaReport{}
+	r.collectErrors("Query")
+	r.claimRootNames(reflect.ValueOf(query))
+	if mutation != nil {
+		r.claimRootNames(reflect.ValueOf(mutation))
+		defer r.registerRootName("Mutation", reflect.ValueOf(mutation))()
+	}
+	defer r.registerRootName("Query", reflect.ValueOf(query))()
+	config := graphql.SchemaConfig{
+		Query: r.reflectRoot("Query", reflect.ValueOf(query), report),
+	}
+	if mutation != nil {
+		r.path = []string{"Mutation"}
+		config.Mutation = r.reflectRoot("Mutation", reflect.ValueOf(mutation), report)
+	}
+	if err := r.err(); err != nil {
+		return graphql.Schema{}, report, err
+	}
+	// Include the implementations of reflected interfaces, which are
+	// reached by the roots along with every other type they refer to, but
+	// not the types of other reflections that share the cache
+	config.Types = r.cache.types(r.claimed)
+	schema, err := graphql.NewSchema(config)
+	return schema, report, err
+}
+
+// DecodeArgs decodes the arguments of the field resolved by p into dst, the
+// same way as the function DecodeArgs does, with fields named the way the
+// Reflector names them.
+func (rf *Reflector) DecodeArgs(p graphql.ResolveParams, dst interface{}) error {
+	return decodeArgs(p, dst, decoder{&rf.options})
+}
+
+// Types returns all the graphql types reflected by the Reflector so far,
+// ordered by name (see TypeCache.Types).
+func (rf *Reflector) Types() []graphql.Type {
+	return rf.cache.Types()
+}
@@ -0,0 +1,58 @@
+package reflector
+
+import (
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// JSON is an opaque graphql scalar, serialized as is. Its value may be
+// any JSON value, for example an object with arbitrary keys.
+var JSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "JSON",
+	Description: "The `JSON` scalar type represents an arbitrary JSON value, " +
+		"such as an object with arbitrary keys.",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: parseJSONLiteral,
+})
+
+// Translate an AST value into the go value that represents it in JSON
+func parseJSONLiteral(valueAST ast.Value) interface{} {
+	switch valueAST := valueAST.(type) {
+	case *ast.StringValue:
+		return valueAST.Value
+	case *ast.BooleanValue:
+		return valueAST.Value
+	case *ast.EnumValue:
+		return valueAST.Value
+	case *ast.IntValue:
+		if i, err := strconv.ParseInt(valueAST.Value, 10, 64); err == nil {
+			return i
+		}
+		// Too big for an int64, use a float instead
+		f, _ := strconv.ParseFloat(valueAST.Value, 64)
+		return f
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(valueAST.Value, 64)
+		return f
+	case *ast.ListValue:
+		values := make([]interface{}, len(valueAST.Values))
+		for i, v := range valueAST.Values {
+			values[i] = parseJSONLiteral(v)
+		}
+		return values
+	case *ast.ObjectValue:
+		values := make(map[string]interface{}, len(valueAST.Fields))
+		for _, f := range valueAST.Fields {
+			values[f.Name.Value] = parseJSONLiteral(f.Value)
+		}
+		return values
+	}
+	return nil
+}
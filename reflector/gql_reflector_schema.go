@@ -0,0 +1,227 @@
+package reflector
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unicode"
+
+	"github.com/graphql-go/graphql"
+)
+
+// SchemaReport describes how the go root values were mapped into a schema by
+// BuildSchemaFq
+type SchemaReport struct {
+	// Fields are the root fields of the schema
+	Fields []MappedField
+	// Skipped are the exported methods and tagged fields of the root values
+	// that could not be mapped
+	Skipped []SkippedMember
+}
+
+// MappedField is a root field of a schema built by BuildSchemaFq
+type MappedField struct {
+	// Root is the name of the root type, Query or Mutation
+	Root string
+	// Name is the name of the root field
+	Name GqlName
+	// GoName is the name of the method or struct field it was mapped from
+	GoName GoName
+	// Type is the graphql type of the field
+	Type string
+}
+
+// SkippedMember is a method or a struct field of a root value that was not
+// mapped by BuildSchemaFq
+type SkippedMember struct {
+	Root   string
+	GoName GoName
+	Reason string
+}
+
+// String returns a human readable description of the report, one line per
+// root field
+func (report *SchemaReport) String() string {
+	var buf bytes.Buffer
+	for _, f := range report.Fields {
+		fmt.Fprintf(&buf, "%s.%s: %s <- %s\n", f.Root, f.Name, f.Type, f.GoName)
+	}
+	for _, s := range report.Skipped {
+		fmt.Fprintf(&buf, "%s skipped %s: %s\n", s.Root, s.GoName, s.Reason)
+	}
+	return buf.String()
+}
+
+// BuildSchema is a shorthand method for invoking BuildSchemaFq with the
+// default type map and no exclude tags at all.
+func BuildSchema(
+	query interface{},
+	mutation interface{},
+	opts ...Option,
+) (graphql.Schema, *SchemaReport, error) {
+	return BuildSchemaFq(query, mutation, GetDefaultTypeMap(), ExcludeFieldTag(""), opts...)
+}
+
+// BuildSchemaFq builds a graphql schema whose Query and Mutation root types
+// are reflected from the go values query and mutation. mutation may be nil.
+// The root fields are the exported methods of the root values, reflected the
+// same way as ReflectFuncFq reflects functions and named in lower camel case
+// (GetUser becomes getUser), along with the fields tagged by a json tag of the
+// root values, if they're structs. Tagged fields that hold a function are
+// reflected as functions as well.
+// Methods and fields that can not be reflected are skipped, and listed in the
+// returned report.
+func BuildSchemaFq(
+	query interface{},
+	mutation interface{},
+	typeMap TypeMap,
+	exclude ExcludeFieldTag,
+	opts ...Option,
+) (graphql.Schema, *SchemaReport, error) {
+	return newReflector(typeMap, exclude, opts).Schema(query, mutation)
+}
+
+// Reflect the root object, named name, from the fields and methods of v
+func (r *reflection) reflectRoot(
+	name string,
+	v reflect.Value,
+	report *SchemaReport,
+) *graphql.Object {
+	fields := make(graphql.Fields)
+	addField := func(goName GoName, field *graphql.Field) {
+		if _, exists := fields[field.Name]; exists {
+			report.Skipped = append(report.Skipped, SkippedMember{
+				Root:   name,
+				GoName: goName,
+				Reason: fmt.Sprintf("duplicate field name %s", field.Name),
+			})
+			return
+		}
+		fields[field.Name] = field
+		report.Fields = append(report.Fields, MappedField{
+			Root:   name,
+			Name:   GqlName(field.Name),
+			GoName: goName,
+			Type:   field.Type.String(),
+		})
+	}
+
+	if s := indirectValue(v); s.Kind() == reflect.Struct {
+		t := s.Type()
+		for _, f := range structFields(t, r.naming) {
+			if !includeStructField(f, r.exclude) {
+				continue
+			}
+			fieldName := f.name
+			pop := r.at(f.goPath())
+			r.checkName(f, fieldName)
+			if f.Type.Kind() != reflect.Func {
+				field := r.reflectStructField(GqlName(name), t, f)
+				resolve := field.Resolve
+				root := v.Interface()
+				field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+					p.Source = root
+					return resolve(p)
+				}
+				addField(GoName(f.Name), field)
+				pop()
+				continue
+			}
+			fn := fieldByIndex(s, f.Index)
+			if _, err := parseFuncSignature(f.Type, 0); err != nil || !fn.IsValid() || fn.IsNil() {
+				if err == nil {
+					err = fmt.Errorf("nil function")
+				}
+				report.Skipped = append(report.Skipped, SkippedMember{
+					Root:   name,
+					GoName: GoName(f.Name),
+					Reason: err.Error(),
+				})
+				pop()
+				continue
+			}
+			field := r.reflectFunc(fieldName, fn)
+			describeField(f.StructField, field)
+			addField(GoName(f.Name), field)
+			pop()
+		}
+	}
+
+	for i := 0; i < v.NumMethod(); i++ {
+		m := v.Type().Method(i)
+		method := v.Method(i)
+		if isReflectorMethod(m) {
+			continue
+		}
+		if _, err := parseFuncSignature(method.Type(), 0); err != nil {
+			report.Skipped = append(report.Skipped, SkippedMember{
+				Root:   name,
+				GoName: GoName(m.Name),
+				Reason: err.Error(),
+			})
+			continue
+		}
+		pop := r.at("." + m.Name + "()")
+		addField(GoName(m.Name), r.reflectFunc(r.naming.casing.method(m.Name), method))
+		pop()
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:   name,
+		Fields: fields,
+	})
+}
+
+// Whether m is a method of the interfaces by which go types configure their
+// reflection, such as GqlMethodFields, rather than a root field
+func isReflectorMethod(m reflect.Method) bool {
+	for _, iface := range []reflect.Type{methodFielderType, describerType} {
+		if _, exists := iface.MethodByName(m.Name); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// Register name as the name of the root object reflected from the value v,
+// so no other type may be named after it, until the returned function is
+// called. Root objects are not cached, so their names aren't either.
+func (r *reflection) registerRootName(name GqlName, v reflect.Value) func() {
+	t := v.Type()
+	previous, named := r.cache.names[name]
+	if named && previous != t && r.claimed[previous] {
+		r.fail(previous, "GQL type name collision: both %s and %s are named %s",
+			previous, t, name)
+	}
+	r.cache.names[name] = t
+	return func() {
+		if named {
+			r.cache.names[name] = previous
+		} else {
+			delete(r.cache.names, name)
+		}
+	}
+}
+
+// Claim the names of the types reachable from the fields and methods of the
+// root value v (see claimNames)
+func (r *reflection) claimRootNames(v reflect.Value) {
+	r.claimNames(v.Type())
+	for i := 0; i < v.NumMethod(); i++ {
+		r.claimNames(v.Method(i).Type())
+	}
+}
+
+// Lower the leading upper case letters of the go name s, keeping the last one
+// if it starts a new word. For example GetUser becomes getUser, ID becomes id,
+// and URLFor becomes urlFor.
+func lowerCamelCase(s string) GqlName {
+	runes := []rune(s)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return GqlName(string(runes))
+}
@@ -0,0 +1,309 @@
+package reflector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type schemaUserArgs struct {
+	ID int `json:"id" gqlnull:"required"`
+}
+
+type schemaQuery struct {
+	Motd   string                           `json:"motd"`
+	Echo   func(args schemaEchoArgs) string `json:"echo"`
+	NilFn  func() string                    `json:"nil_fn"`
+	Hidden string
+	users  map[int]*schemaUser
+}
+
+type schemaEchoArgs struct {
+	Text string `json:"text"`
+}
+
+func (q *schemaQuery) GetUser(ctx context.Context, args schemaUserArgs) (*schemaUser, error) {
+	return q.users[args.ID], nil
+}
+
+func (q *schemaQuery) Users() []*schemaUser {
+	return []*schemaUser{q.users[1], q.users[2]}
+}
+
+func (q *schemaQuery) Unsupported(id int) string {
+	return ""
+}
+
+type schemaMutation struct {
+	query *schemaQuery
+}
+
+func (m schemaMutation) SetMotd(args struct {
+	Motd string `json:"motd"`
+}) string {
+	m.query.Motd = args.Motd
+	return m.query.Motd
+}
+
+func TestBuildSchema(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+
+	query := &schemaQuery{
+		Motd: "hello",
+		Echo: func(args schemaEchoArgs) string { return args.Text },
+		users: map[int]*schemaUser{
+			1: {ID: 1, Name: "one"},
+			2: {ID: 2, Name: "two"},
+		},
+	}
+	schema, report, err := BuildSchema(query, schemaMutation{query: query})
+	req.Nil(err)
+	as.Equal([]MappedField{
+		{Root: "Query", Name: "motd", GoName: "Motd", Type: "String"},
+		{Root: "Query", Name: "echo", GoName: "Echo", Type: "String"},
+		{Root: "Query", Name: "getUser", GoName: "GetUser", Type: "schemaUser"},
+		{Root: "Query", Name: "users", GoName: "Users", Type: "[schemaUser]"},
+		{Root: "Mutation", Name: "setMotd", GoName: "SetMotd", Type: "String"},
+	}, report.Fields)
+	req.Len(report.Skipped, 2)
+	as.Equal(GoName("NilFn"), report.Skipped[0].GoName)
+	as.Equal("nil function", report.Skipped[0].Reason)
+	as.Equal(GoName("Unsupported"), report.Skipped[1].GoName)
+	as.Contains(report.String(), "Query.getUser: schemaUser <- GetUser\n")
+
+	do := func(request string) string {
+		r := graphql.Do(graphql.Params{Schema: schema, RequestString: request})
+		req.Empty(r.Errors)
+		result, err := json.Marshal(r.Data)
+		req.Nil(err)
+		return string(result)
+	}
+	as.JSONEq(`{
+		"motd": "hello",
+		"echo": "echo",
+		"getUser": {"name": "two"},
+		"users": [{"id": 1}, {"id": 2}]
+	}`, do(`{motd echo(text: "echo") getUser(id: 2) {name} users {id}}`))
+	as.JSONEq(`{"setMotd": "bye"}`, do(`mutation {setMotd(motd: "bye")}`))
+	as.JSONEq(`{"motd": "bye"}`, do(`{motd}`))
+}
+
+func TestReflectorSchema(t *testing.T) {
+	type Other struct {
+		X int `json:"x"`
+	}
+	as := assert.New(t)
+	req := require.New(t)
+
+	rf := New()
+	_, err := rf.Type(Other{})
+	req.Nil(err)
+	schema, _, err := rf.Schema(&schemaQuery{}, nil)
+	req.Nil(err)
+	as.NotNil(schema.Type("schemaUser"))
+	// Types reflected apart from the roots are left out
+	as.Nil(schema.Type("Other"))
+	_, _, err = rf.Schema(&schemaQuery{}, nil)
+	as.Nil(err)
+}
+
+func TestBuildSchemaErrors(t *testing.T) {
+	_, _, err := BuildSchema(nil, nil)
+	assert.NotNil(t, err)
+
+	_, _, err = BuildSchema(struct{}{}, nil)
+	assert.NotNil(t, err)
+
+	// A type may not be named after a root
+	type Query struct {
+		X int `json:"x"`
+	}
+	_, _, err = BuildSchema(struct {
+		Q Query `json:"q"`
+	}{}, nil)
+	assert.Contains(t, fmt.Sprint(err), "are named Query")
+}
+
+// A root that configures its reflection, which doesn't make root fields
+type schemaConfiguredRoot struct{}
+
+func (schemaConfiguredRoot) GqlMethodFields() []MethodField {
+	return nil
+}
+
+func (schemaConfiguredRoot) GqlDescription() string {
+	return "The root"
+}
+
+func (schemaConfiguredRoot) Ping() string {
+	return "pong"
+}
+
+func TestBuildSchemaReflectorMethods(t *testing.T) {
+	_, report, err := BuildSchema(schemaConfiguredRoot{}, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []MappedField{
+		{Root: "Query", Name: "ping", GoName: "Ping", Type: "String"},
+	}, report.Fields)
+	assert.Empty(t, report.Skipped)
+}
+
+type schemaPayment interface {
+	Amount() float64
+	Describe(currency string) string
+}
+
+type schemaCard struct {
+	Number string `json:"number"`
+	total  float64
+}
+
+func (c schemaCard) Amount() float64 {
+	return c.total
+}
+
+func (c schemaCard) Describe(currency string) string {
+	return ""
+}
+
+type schemaCash struct {
+	total float64
+}
+
+func (c *schemaCash) Amount() float64 {
+	return c.total
+}
+
+func (c *schemaCash) Describe(currency string) string {
+	return ""
+}
+
+type schemaNamed struct {
+	Name string `json:"name"`
+}
+
+type schemaPolymorphicQuery struct {
+	Payments []schemaPayment `json:"payments"`
+	Search   []interface{}   `json:"search"`
+}
+
+func TestPolymorphicTypes(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+
+	query := schemaPolymorphicQuery{
+		Payments: []schemaPayment{schemaCard{Number: "4242", total: 5}, &schemaCash{total: 2}},
+		Search:   []interface{}{schemaUser{ID: 1}, &schemaNamed{Name: "n"}},
+	}
+	_, _, err := BuildSchema(query, nil)
+	req.Nil(err)
+
+	cache := NewTypeCache()
+	opts := []Option{
+		WithTypeCache(cache),
+		WithInterface((*schemaPayment)(nil), nil, schemaCard{}, &schemaCash{}),
+		WithUnion((*interface{})(nil), schemaUser{}, schemaNamed{}),
+	}
+	schema, _, err := BuildSchema(query, nil, opts...)
+	req.Nil(err)
+	payment := schema.Type("schemaPayment")
+	req.IsType(&graphql.Interface{}, payment)
+	as.Contains(payment.(*graphql.Interface).Fields(), "amount")
+	as.NotContains(payment.(*graphql.Interface).Fields(), "describe")
+	req.IsType(&graphql.Union{}, schema.Type("Query_search"))
+	as.Len(schema.Type("schemaCash").(*graphql.Object).Interfaces(), 1)
+	as.Contains(cache.Types(), schema.Type("schemaCard"))
+
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: `{
+		payments {amount ... on schemaCard {number}}
+		search {... on schemaUser {id} ... on schemaNamed {name}}
+	}`})
+	req.Empty(r.Errors)
+	result, err := json.Marshal(r.Data)
+	req.Nil(err)
+	as.JSONEq(`{
+		"payments": [{"amount": 5, "number": "4242"}, {"amount": 2}],
+		"search": [{"id": 1}, {"name": "n"}]
+	}`, string(result))
+
+	as.Panics(func() { WithInterface(schemaCard{}, nil) })
+	as.Panics(func() { WithUnion((*schemaPayment)(nil), schemaNamed{}) })
+}
+
+type schemaPriced interface {
+	UnitPrice() float64
+}
+
+type schemaItem struct {
+	ItemName string `json:"item_name"`
+}
+
+func (schemaItem) GqlMethodFields() []MethodField {
+	return []MethodField{{Method: "TotalPrice", Args: []GqlName{"count"}}}
+}
+
+func (schemaItem) UnitPrice() float64 {
+	return 2
+}
+
+func (i schemaItem) TotalPrice(count int) float64 {
+	return i.UnitPrice() * float64(count)
+}
+
+type schemaItemQuery struct {
+	Priced []schemaPriced `json:"priced"`
+}
+
+func (schemaItemQuery) FirstItem() schemaItem {
+	return schemaItem{ItemName: "pen"}
+}
+
+func TestMethodFieldCasing(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+
+	query := schemaItemQuery{Priced: []schemaPriced{schemaItem{}}}
+	schema, report, err := BuildSchema(query, nil, WithFieldCasing(SnakeCase),
+		WithInterface((*schemaPriced)(nil), nil, schemaItem{}))
+	req.Nil(err)
+	as.Contains(report.Fields, MappedField{
+		Root: "Query", Name: "first_item", GoName: "FirstItem", Type: "schemaItem"})
+	as.Contains(schema.Type("schemaPriced").(*graphql.Interface).Fields(), "unit_price")
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: `{
+		first_item {item_name total_price(count: 3) unit_price}
+		priced {unit_price}
+	}`})
+	req.Empty(r.Errors)
+	result, err := json.Marshal(r.Data)
+	req.Nil(err)
+	as.JSONEq(`{
+		"first_item": {"item_name": "pen", "total_price": 6, "unit_price": 2},
+		"priced": [{"unit_price": 2}]
+	}`, string(result))
+
+	// Methods are named in lower camel case by default
+	schema, _, err = BuildSchema(query, nil, WithInterface((*schemaPriced)(nil), nil, schemaItem{}))
+	req.Nil(err)
+	as.Contains(schema.QueryType().Fields(), "firstItem")
+	as.Contains(schema.Type("schemaItem").(*graphql.Object).Fields(), "totalPrice")
+	as.Contains(schema.Type("schemaPriced").(*graphql.Interface).Fields(), "unitPrice")
+}
+
+func TestLowerCamelCase(t *testing.T) {
+	as := assert.New(t)
+	as.Equal(GqlName("getUser"), lowerCamelCase("GetUser"))
+	as.Equal(GqlName("id"), lowerCamelCase("ID"))
+	as.Equal(GqlName("urlFor"), lowerCamelCase("URLFor"))
+	as.Equal(GqlName("a"), lowerCamelCase("A"))
+}
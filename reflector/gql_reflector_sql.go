@@ -0,0 +1,137 @@
+package reflector
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+var (
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	nullTimeType = reflect.TypeOf(sql.NullTime{})
+	// The database/sql null types, whose invalid values resolve to null
+	sqlNullTypes = sqlNullTypeMap()
+)
+
+// The mappings of the database/sql null types to the scalars of their valid
+// values. Invalid values resolve to null.
+func sqlNullTypeMap() TypeMap {
+	return TypeMap{
+		reflect.TypeOf(sql.NullString{}): {
+			Output:   graphql.String,
+			Resolver: valuerResolver,
+		},
+		reflect.TypeOf(sql.NullInt64{}): {
+			Output:   graphql.Int,
+			Resolver: valuerResolver,
+		},
+		reflect.TypeOf(sql.NullInt32{}): {
+			Output:   graphql.Int,
+			Resolver: valuerResolver,
+		},
+		reflect.TypeOf(sql.NullFloat64{}): {
+			Output:   graphql.Float,
+			Resolver: valuerResolver,
+		},
+		reflect.TypeOf(sql.NullBool{}): {
+			Output:   graphql.Boolean,
+			Resolver: valuerResolver,
+		},
+		nullTimeType: {
+			Output:   DateTime,
+			Resolver: valuerResolver,
+		},
+	}
+}
+
+// Whether t is an array type, or a struct type without exported fields, that
+// implements driver.Valuer, and is hence reflected as a JSON scalar of its
+// value, rather than by its kind, unless it marshals into text, such as a
+// UUID. Structs with exported fields are reflected as objects, and valuers of
+// other kinds, such as strings, by their kind as usual.
+func isValuer(t reflect.Type) bool {
+	return isOpaque(t) && implements(t, valuerType)
+}
+
+// Whether values of type t may resolve to null as valuers, such as invalid
+// sql.NullString values
+func isNullValuer(t reflect.Type) bool {
+	_, isNull := sqlNullTypes[t]
+	return isNull || isValuer(t)
+}
+
+// Whether t is an array type, or a struct type without exported fields, that
+// implements sql.Scanner, and is hence decoded by scanning the input value
+func isScanner(t reflect.Type) bool {
+	return isOpaque(t) && reflect.PtrTo(t).Implements(scannerType)
+}
+
+// Whether the values of type t have no structure that may be reflected, so
+// they may only be reflected by the database/sql interfaces they implement
+func isOpaque(t reflect.Type) bool {
+	return t.Kind() == reflect.Array || (t.Kind() == reflect.Struct && !hasExportedFields(t))
+}
+
+// Whether input values of type t are decoded by scanning them, either since t
+// is a scanner, or since the type map maps it, such as sql.NullString, and its
+// pointer implements sql.Scanner
+func (o *options) scans(t reflect.Type) bool {
+	if _, exists := o.typeMap[t]; exists {
+		return reflect.PtrTo(t).Implements(scannerType)
+	}
+	return isScanner(t)
+}
+
+// Resolves a driver.Valuer, such as sql.NullString, to its value, which is nil
+// for invalid null types. Times are formatted in RFC3339, and bytes as strings.
+func valuerResolver(p graphql.ResolveParams) (interface{}, error) {
+	value := GetValueFromResolveParams(p)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	valuer, ok := value.Interface().(driver.Valuer)
+	if !ok {
+		// Valuers with a pointer receiver need an addressable value
+		valuer, _ = addressable(value).Addr().Interface().(driver.Valuer)
+	}
+	if valuer == nil {
+		// Such as a value held by a map source
+		return valueInterface(value), nil
+	}
+	v, err := valuer.Value()
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), nil
+	case []byte:
+		return string(v), nil
+	}
+	return v, nil
+}
+
+// Decode the graphql input value src into dst, whose pointer is an
+// sql.Scanner. Times are parsed by DateTime, or given in RFC3339.
+func decodeScanner(path string, src interface{}, dst reflect.Value) error {
+	if s, ok := src.(string); ok && dst.Type() == nullTimeType {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("cannot decode argument %q: %s", path, err)
+		}
+		src = t
+	}
+	if i, ok := src.(int); ok {
+		// Scanners only handle the types of driver.Value
+		src = int64(i)
+	}
+	if err := dst.Addr().Interface().(sql.Scanner).Scan(src); err != nil {
+		return fmt.Errorf("cannot decode argument %q: %s", path, err)
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 package reflector
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"image"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -38,15 +46,15 @@ func TestStringKind(t *testing.T) {
 	assertQuery(t, f, "s", "", `{"data":{"s": "sss"}}`, "")
 }
 func TestSimpleStruct(t *testing.T) {
-	type S struct {
+	type Simple struct {
 		A string `json:"a"`
 	}
 
-	gqlt := ReflectTypeFq("s", reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	gqlt := ReflectTypeFq("s", reflect.TypeOf(Simple{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
 	f := graphql.Field{
 		Type: gqlt,
 		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-			return S{
+			return Simple{
 				A: "hello world",
 			}, nil
 		},
@@ -55,15 +63,15 @@ func TestSimpleStruct(t *testing.T) {
 }
 
 func TestSimpleDefaultReflectType(t *testing.T) {
-	type S struct {
+	type SimpleDefault struct {
 		A string `json:"a"`
 	}
 
-	gqlt := ReflectType(S{})
+	gqlt := ReflectType(SimpleDefault{})
 	f := graphql.Field{
 		Type: gqlt,
 		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-			return S{
+			return SimpleDefault{
 				A: "hello world",
 			}, nil
 		},
@@ -94,16 +102,16 @@ func TestExclude(t *testing.T) {
 }
 
 func TestFieldsWithoutJSON(t *testing.T) {
-	type S struct {
+	type WithoutJSON struct {
 		A string `json:"a"`
 		B string
 	}
 
-	gqlt := ReflectTypeFq("s", reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag("ignore"))
+	gqlt := ReflectTypeFq("s", reflect.TypeOf(WithoutJSON{}), GetDefaultTypeMap(), ExcludeFieldTag("ignore"))
 	f := graphql.Field{
 		Type: gqlt,
 		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-			return S{
+			return WithoutJSON{
 				A: "hello world",
 				B: "hello world",
 			}, nil
@@ -312,6 +320,981 @@ func TestArray(t *testing.T) {
 	assertQuery(t, f, "s", "", `{"data":{"s":["hello", "world"]}}`, "")
 }
 
+func TestPointers(t *testing.T) {
+	type PointerSub struct {
+		X string `json:"x"`
+	}
+	type Pointers struct {
+		Str     *string       `json:"str"`
+		NilStr  *string       `json:"nil_str"`
+		Time    *time.Time    `json:"time"`
+		NilTime *time.Time    `json:"nil_time"`
+		Sub     *PointerSub   `json:"sub"`
+		NilSub  *PointerSub   `json:"nil_sub"`
+		Subs    []*PointerSub `json:"subs"`
+		PtrPtr  **int         `json:"ptr_ptr"`
+	}
+
+	str := "hello world"
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	i := 5
+	pi := &i
+	gqlt := ReflectType(&Pointers{})
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return &Pointers{
+				Str:    &str,
+				Time:   &tm,
+				Sub:    &PointerSub{X: "x"},
+				Subs:   []*PointerSub{{X: "a"}, nil, {X: "b"}},
+				PtrPtr: &pi,
+			}, nil
+		},
+	}
+	assertQuery(t, f, "s", `{
+		str
+		nil_str
+		time
+		nil_time
+		sub { x }
+		nil_sub { x }
+		subs { x }
+		ptr_ptr
+	}`, `{"data":{"s":{
+		"str": "hello world",
+		"nil_str": null,
+		"time": "2009-11-10T23:00:00Z",
+		"nil_time": null,
+		"sub": {"x": "x"},
+		"nil_sub": null,
+		"subs": [{"x": "a"}, null, {"x": "b"}],
+		"ptr_ptr": 5
+	}}}`, "")
+}
+
+func TestNullability(t *testing.T) {
+	type S struct {
+		A   string      `json:"a"`
+		P   *string     `json:"p"`
+		O   string      `json:"o,omitempty"`
+		I   int         `json:"i" gqlnull:"nullable"`
+		R   *int        `json:"r" gqlnull:"required"`
+		L   []string    `json:"l"`
+		LP  []*string   `json:"lp"`
+		LN  []string    `json:"ln" gqlnull:"nullable,elem_nullable"`
+		LR  []*string   `json:"lr" gqlnull:"elem_required"`
+		Any interface{} `json:"any"`
+	}
+	as := assert.New(t)
+
+	typeNames := func(fields graphql.Fields) map[string]string {
+		names := make(map[string]string)
+		for name, f := range fields {
+			names[name] = f.Type.String()
+		}
+		return names
+	}
+
+	fields := ReflectFieldsFq(reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Equal(map[string]string{
+		"a":   "String",
+		"p":   "String",
+		"o":   "String",
+		"i":   "Int",
+		"r":   "Int!",
+		"l":   "[String]",
+		"lp":  "[String]",
+		"ln":  "[String]",
+		"lr":  "[String!]",
+		"any": "String",
+	}, typeNames(fields))
+
+	fields = ReflectFieldsFq(reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithNullability(InferNonNull))
+	as.Equal(map[string]string{
+		"a":   "String!",
+		"p":   "String",
+		"o":   "String",
+		"i":   "Int",
+		"r":   "Int!",
+		"l":   "[String!]!",
+		"lp":  "[String]!",
+		"ln":  "[String]",
+		"lr":  "[String!]!",
+		"any": "String",
+	}, typeNames(fields))
+}
+
+func TestNonNullQuery(t *testing.T) {
+	type S struct {
+		A string  `json:"a"`
+		P *string `json:"p" gqlnull:"required"`
+	}
+
+	gqlt := ReflectTypeFq("s", reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithNullability(InferNonNull))
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return S{A: "hello world"}, nil
+		},
+	}
+	assertQuery(t, f, "s", "{a}", `{"data":{"s":{"a":"hello world"}}}`, "")
+	assertQuery(t, f, "s", "{a p}", "", "Cannot return null for non-nullable field")
+
+	// Nil slices resolve to empty lists, since lists are non-null
+	type L struct {
+		Tags []string         `json:"tags"`
+		Sets map[string][]int `json:"sets"`
+	}
+	lf := ReflectFuncFq("l", func() []L {
+		return []L{{Sets: map[string][]int{"a": nil}}}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull))
+	assert.Equal(t, "[L]!", lf.Type.String())
+	assertQuery(t, *lf, "l", "{tags sets {key value}}",
+		`{"data":{"l":[{"tags":[],"sets":[{"key":"a","value":[]}]}]}}`, "")
+	nf := ReflectFuncFq("n", func() []string { return nil },
+		GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull))
+	assertQuery(t, *nf, "n", "", `{"data":{"n":[]}}`, "")
+}
+
+func TestMaps(t *testing.T) {
+	type MapSub struct {
+		X string `json:"x"`
+	}
+	type Maps struct {
+		Ints    map[string]int            `json:"ints"`
+		Subs    map[string]*MapSub        `json:"subs"`
+		Nested  map[string]map[int]string `json:"nested"`
+		Many    []map[string]int          `json:"many"`
+		NilMap  map[string]int            `json:"nil_map"`
+		NoMap   map[string]int            `json:"no_map" gqlnull:"required"`
+		JSONMap map[string]MapSub         `json:"json_map" gqlmap:"json"`
+	}
+
+	gqlt := ReflectType(Maps{})
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return Maps{
+				Ints: map[string]int{"c": 3, "a": 1, "b": 2},
+				Subs: map[string]*MapSub{"b": {X: "bb"}, "a": nil},
+				Nested: map[string]map[int]string{
+					"n": {10: "ten", 2: "two"},
+				},
+				Many:    []map[string]int{{"y": 1, "x": 2}, {}},
+				JSONMap: map[string]MapSub{"a": {X: "aa"}},
+			}, nil
+		},
+	}
+	assertQuery(t, f, "s", `{
+		ints { key value }
+		subs { key value { x } }
+		nested { key value { key value } }
+		many { key value }
+		nil_map { key value }
+		no_map { key value }
+		json_map
+	}`, `{"data":{"s":{
+		"ints": [
+			{"key": "a", "value": 1},
+			{"key": "b", "value": 2},
+			{"key": "c", "value": 3}
+		],
+		"subs": [
+			{"key": "a", "value": null},
+			{"key": "b", "value": {"x": "bb"}}
+		],
+		"nested": [
+			{"key": "n", "value": [{"key": 2, "value": "two"}, {"key": 10, "value": "ten"}]}
+		],
+		"many": [
+			[{"key": "x", "value": 2}, {"key": "y", "value": 1}],
+			[]
+		],
+		"nil_map": null,
+		"no_map": [],
+		"json_map": {"a": {"x": "aa"}}
+	}}}`, "")
+}
+
+type recursiveNode struct {
+	Name     string          `json:"name"`
+	Children []recursiveNode `json:"children"`
+	Next     *recursiveNode  `json:"next"`
+}
+
+type mutualA struct {
+	Name string   `json:"name"`
+	B    *mutualB `json:"b"`
+}
+
+type mutualB struct {
+	Name string   `json:"name"`
+	A    *mutualA `json:"a"`
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	gqlt := ReflectType(recursiveNode{})
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return recursiveNode{
+				Name: "root",
+				Children: []recursiveNode{
+					{Name: "child", Children: []recursiveNode{{Name: "grandchild"}}},
+				},
+				Next: &recursiveNode{Name: "next"},
+			}, nil
+		},
+	}
+	assertQuery(t, f, "tree", `{
+		name
+		children { name children { name children { name } } }
+		next { name next { name } }
+	}`, `{"data":{"tree":{
+		"name": "root",
+		"children": [
+			{"name": "child", "children": [{"name": "grandchild", "children": []}]}
+		],
+		"next": {"name": "next", "next": null}
+	}}}`, "")
+}
+
+func TestMutuallyRecursiveTypes(t *testing.T) {
+	gqlt := ReflectType(mutualA{})
+	f := graphql.Field{
+		Type: gqlt,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			a := &mutualA{Name: "a1"}
+			a.B = &mutualB{Name: "b1", A: a}
+			return a, nil
+		},
+	}
+	assertQuery(t, f, "a", "{name b { name a { name b { name } } } }",
+		`{"data":{"a":{"name":"a1","b":{"name":"b1","a":{"name":"a1","b":{"name":"b1"}}}}}}`, "")
+}
+
+type embeddedBase struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type embeddedAudit struct {
+	Name string `json:"name"`
+}
+
+type embeddedMeta struct {
+	Version int `json:"version"`
+}
+
+type embeddedOther struct {
+	Created string `json:"created"`
+}
+
+type embeddedAudited struct {
+	embeddedAudit
+	Created string `json:"created"`
+}
+
+type embeddedRecord struct {
+	embeddedBase
+	*embeddedMeta
+	embeddedAudited
+	Tagged embeddedBase `json:"tagged"`
+	Title  string       `json:"title"`
+	Hidden embeddedBase `json:"-"`
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	as := assert.New(t)
+	fields := ReflectFieldsFq(reflect.TypeOf(embeddedRecord{}), GetDefaultTypeMap(),
+		ExcludeFieldTag(""), WithNullability(InferNonNull))
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	// name shadows the deeper embeddedAudit name
+	as.ElementsMatch([]string{"id", "name", "version", "created", "tagged", "title"}, names)
+	as.Equal("Int!", fields["id"].Type.String())
+	as.Equal("Int", fields["version"].Type.String())
+	as.Equal("embeddedBase!", fields["tagged"].Type.String())
+
+	record := embeddedRecord{
+		embeddedBase: embeddedBase{ID: 1, Name: "base"},
+		Tagged:       embeddedBase{ID: 2},
+		Title:        "title",
+	}
+	f := graphql.Field{
+		Type: ReflectType(embeddedRecord{}),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return &record, nil
+		},
+	}
+	assertQuery(t, f, "r", "{id name version title tagged {id}}",
+		`{"data":{"r":{"id":1,"name":"base","version":null,"title":"title","tagged":{"id":2}}}}`, "")
+	record.embeddedMeta = &embeddedMeta{Version: 3}
+	assertQuery(t, f, "r", "{version}", `{"data":{"r":{"version":3}}}`, "")
+
+	var decoded embeddedRecord
+	as.Nil(DecodeInput(map[string]interface{}{"id": 4, "title": "t"}, &decoded))
+	as.Equal(4, decoded.ID)
+	as.Equal("t", decoded.Title)
+	// Like encoding/json, embedded pointers to unexported structs can't be set
+	err := DecodeInput(map[string]interface{}{"version": 5}, &decoded)
+	as.EqualError(err, `cannot decode argument "version": `+
+		`cannot set embedded pointer to unexported struct reflector.embeddedMeta`)
+
+	// Fields of the same name at the same depth shadow each other. The struct
+	// is built at runtime, since go vet rejects such json tags.
+	tie := reflect.StructOf([]reflect.StructField{
+		{Name: "Audited", Type: reflect.TypeOf(embeddedAudited{}), Anonymous: true},
+		{Name: "Other", Type: reflect.TypeOf(embeddedOther{}), Anonymous: true},
+	})
+	tied := structFields(tie, defaultFieldNaming)
+	as.Len(tied, 1)
+	as.Equal(GqlName("name"), tied[0].name)
+	as.Equal([]int{0, 0, 0}, tied[0].Index)
+}
+
+type describedUser struct {
+	Name  string       `json:"name" gqldesc:"The full name"`
+	Login string       `json:"login" gqldeprecated:"use name instead"`
+	State enumStatus   `json:"state"`
+	Tags  []describedT `json:"tags"`
+}
+
+func (describedUser) GqlDescription() string {
+	return "A registered user"
+}
+
+func (describedUser) FullName() string {
+	return ""
+}
+
+func (describedUser) GqlMethodFields() []MethodField {
+	return []MethodField{
+		{Method: "FullName", Description: "Computed", DeprecationReason: "use name"},
+	}
+}
+
+type describedT struct {
+	Value string `json:"value" gqldesc:"The tag"`
+}
+
+func (*describedT) GqlDescription() string {
+	return "A tag"
+}
+
+func TestDescriptions(t *testing.T) {
+	as := assert.New(t)
+	obj := ReflectType(describedUser{}).(*graphql.Object)
+	as.Equal("A registered user", obj.Description())
+	fields := obj.Fields()
+	as.Equal("The full name", fields["name"].Description)
+	as.Equal("", fields["name"].DeprecationReason)
+	as.Equal("use name instead", fields["login"].DeprecationReason)
+	as.Equal("Computed", fields["fullName"].Description)
+	as.Equal("use name", fields["fullName"].DeprecationReason)
+	as.Equal("A tag", fields["tags"].Type.(*graphql.List).OfType.Description())
+
+	input := ReflectInputType(describedT{}).(*graphql.InputObject)
+	as.Equal("A tag", input.Description())
+	as.Equal("The tag", input.Fields()["value"].Description())
+
+	as.Equal("", ReflectType(enumStatus("")).Description())
+}
+
+type sourceItem struct {
+	Name    string            `json:"name"`
+	Created time.Time         `json:"created"`
+	Tags    map[string]int    `json:"tags"`
+	Parent  *sourceItem       `json:"parent"`
+	Items   []sourceItem      `json:"items"`
+	Extra   map[string]string `json:"extra" gqlmap:"json"`
+}
+
+func TestMapSources(t *testing.T) {
+	type Name string
+	var nilItem *sourceItem
+	var held interface{} = &sourceItem{Name: "held"}
+	f := graphql.Field{
+		Type: graphql.NewList(ReflectType(sourceItem{})),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return []interface{}{
+				map[string]interface{}{
+					"name":    "map",
+					"created": "yesterday",
+					"tags":    map[string]interface{}{"a": 1},
+					"parent":  map[Name]interface{}{"name": "parent"},
+					"items":   []interface{}{&held, map[string]string{"name": "m"}},
+				},
+				nilItem,
+				nil,
+				&held,
+			}, nil
+		},
+	}
+	assertQuery(t, f, "items", "{name created tags {key value} parent {name} items {name}}",
+		`{"data":{"items":[
+			{"name":"map","created":"yesterday","tags":[{"key":"a","value":1}],
+				"parent":{"name":"parent"},"items":[{"name":"held"},{"name":"m"}]},
+			null,
+			null,
+			{"name":"held","created":"0001-01-01T00:00:00Z","tags":null,"parent":null,"items":[]}
+		]}}`, "")
+
+	// Fields of sources that have none resolve to null
+	f = graphql.Field{
+		Type: ReflectType(sourceItem{}),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return map[int]string{1: "x"}, nil
+		},
+	}
+	assertQuery(t, f, "item", "{name tags {key} extra}",
+		`{"data":{"item":{"name":null,"tags":null,"extra":null}}}`, "")
+}
+
+func TestFieldNaming(t *testing.T) {
+	as := assert.New(t)
+	type S struct {
+		UserID    int    `gql:"user_id"`
+		FirstName string `json:"first"`
+		LastName  string
+		Skipped   string `gql:"-"`
+		Name      string `gql:"first_name"`
+	}
+	names := func(opts ...Option) []string {
+		fields := ReflectFieldsFq(reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""), opts...)
+		names := []string{}
+		for name := range fields {
+			names = append(names, name)
+		}
+		return names
+	}
+	as.ElementsMatch([]string{"first"}, names())
+	as.ElementsMatch([]string{"user_id", "first_name"}, names(WithTagKey("gql")))
+	as.ElementsMatch([]string{"user_id", "FirstName", "LastName", "first_name"},
+		names(WithTagKey("gql"), WithUntaggedFields(true)))
+	// The tagged Name shadows the untagged FirstName, once both are firstName
+	as.ElementsMatch([]string{"userId", "firstName", "lastName"},
+		names(WithTagKey("gql"), WithUntaggedFields(true), WithFieldCasing(CamelCase)))
+	as.ElementsMatch([]string{"user_id", "first", "last_name", "skipped", "name"},
+		names(WithUntaggedFields(true), WithFieldCasing(SnakeCase)))
+
+	opts := []Option{WithTagKey("gql"), WithUntaggedFields(true), WithFieldCasing(CamelCase)}
+	f := graphql.Field{
+		Type: ReflectTypeFq("S", reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""), opts...),
+		Args: ReflectArgsFq(reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""), opts...),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			var s S
+			err := DecodeArgs(p, &s, opts...)
+			return &s, err
+		},
+	}
+	assertQuery(t, f, "s", `(userId: 1, lastName: "l", firstName: "f") {userId lastName firstName}`,
+		`{"data":{"s":{"userId":1,"lastName":"l","firstName":"f"}}}`, "")
+}
+
+func TestFieldCasing(t *testing.T) {
+	as := assert.New(t)
+	for s, expected := range map[string][2]GqlName{
+		"user_id":      {"userId", "user_id"},
+		"UserID":       {"userId", "user_id"},
+		"userId":       {"userId", "user_id"},
+		"HTTPServerID": {"httpServerId", "http_server_id"},
+		"first-name":   {"firstName", "first_name"},
+		"A":            {"a", "a"},
+		"v2Name":       {"v2Name", "v2_name"},
+	} {
+		as.Equal(expected[0], CamelCase.apply(s), s)
+		as.Equal(expected[1], SnakeCase.apply(s), s)
+		as.Equal(GqlName(s), KeepCase.apply(s), s)
+	}
+}
+
+func TestJSONTagOptions(t *testing.T) {
+	as := assert.New(t)
+	type Options struct {
+		Skipped string   `json:"-"`
+		Dash    string   `json:"-,"`
+		Count   int      `json:"count,omitempty"`
+		Tags    []string `json:"tags,omitempty"`
+		ID      int64    `json:"id,string"`
+		Price   *float64 `json:"price,string"`
+		Name    string   `json:"name,string"`
+		List    []int    `json:"list,string"`
+	}
+	fields := ReflectFieldsFq(reflect.TypeOf(Options{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithNullability(InferNonNull))
+	as.NotContains(fields, "Skipped")
+	as.Contains(fields, "-")
+	as.Equal("Int", fields["count"].Type.String())
+	as.Equal("String!", fields["id"].Type.String())
+	as.Equal("String", fields["price"].Type.String())
+	as.Equal("[Int!]!", fields["list"].Type.String())
+
+	_, err := ReflectTypeFqE("", reflect.TypeOf(Options{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.EqualError(err, `Options.Dash (string): "-" is not a valid graphql name`)
+
+	type T struct {
+		Count int      `json:"count,omitempty"`
+		Tags  []string `json:"tags,omitempty"`
+		ID    int64    `json:"id,string"`
+		Price *float64 `json:"price,string"`
+		Name  string   `json:"name,string"`
+	}
+	price := 1.5
+	value := T{ID: 12, Price: &price, Name: "n"}
+	resolve := func(p graphql.ResolveParams) (interface{}, error) {
+		return value, nil
+	}
+	query := "{count tags id price name}"
+	f := graphql.Field{Type: ReflectType(T{}), Resolve: resolve}
+	assertQuery(t, f, "t", query,
+		`{"data":{"t":{"count":0,"tags":[],"id":"12","price":"1.5","name":"\"n\""}}}`, "")
+	f = graphql.Field{Type: ReflectType(T{}, WithOmitEmptyAsNull(true)), Resolve: resolve}
+	assertQuery(t, f, "t", query,
+		`{"data":{"t":{"count":null,"tags":null,"id":"12","price":"1.5","name":"\"n\""}}}`, "")
+	value = T{Count: 1, Tags: []string{"a"}}
+	assertQuery(t, f, "t", query,
+		`{"data":{"t":{"count":1,"tags":["a"],"id":"0","price":null,"name":"\"\""}}}`, "")
+
+	var decoded T
+	as.Nil(DecodeInput(map[string]interface{}{"id": "7", "price": "2.5", "name": `"m"`}, &decoded))
+	as.Equal(T{ID: 7, Price: func() *float64 { p := 2.5; return &p }(), Name: "m"}, decoded)
+	err = DecodeInput(map[string]interface{}{"id": "x"}, &decoded)
+	as.Contains(err.Error(), `cannot decode argument "id": invalid character`)
+	as.Equal(graphql.String, ReflectInputType(T{}).(*graphql.InputObject).Fields()["id"].Type)
+}
+
+func TestReflector(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+	type Shared struct {
+		X string `json:"x"`
+	}
+	type A struct {
+		S      Shared `json:"s"`
+		Secret string `json:"secret" gqlexclude:"public"`
+		Price  Price  `json:"price"`
+	}
+	type B struct {
+		S Shared `json:"s"`
+	}
+
+	typeMap := GetDefaultTypeMap()
+	typeMap[reflect.TypeOf(Price(0))] = GqlOutputAndResolver{Output: graphql.Float, Resolver: trivialResolver}
+	rf := New(WithTypeMap(typeMap), WithExclude("public"), WithFieldCasing(CamelCase))
+	// The reflector keeps its own copy of the type map
+	delete(typeMap, reflect.TypeOf(Price(0)))
+	_, exists := GetDefaultTypeMap()[reflect.TypeOf(Price(0))]
+	as.False(exists)
+
+	a, err := rf.Type(A{})
+	req.Nil(err)
+	fields := a.(*graphql.Object).Fields()
+	as.NotContains(fields, "secret")
+	as.Equal("Float", fields["price"].Type.String())
+	b, err := rf.TypeFq("b", reflect.TypeOf(B{}))
+	req.Nil(err)
+	// Types are shared by everything the reflector reflects
+	as.Equal(fields["s"].Type, b.(*graphql.Object).Fields()["s"].Type)
+	as.Len(rf.Types(), 3)
+
+	_, err = rf.Type(struct {
+		C chan int `json:"c"`
+	}{})
+	as.Contains(err.Error(), "Unsupported GQL kind chan")
+	_, err = rf.Type(nil)
+	as.NotNil(err)
+	_, err = rf.Func("x", nil)
+	as.Contains(err.Error(), "Cannot reflect field x: nil function")
+	_, err = rf.Func("x", A{})
+	as.Contains(err.Error(), "Cannot reflect field x: reflector.A is not a function")
+	_, err = rf.Func("x", func() {})
+	as.Contains(err.Error(), "Cannot reflect field x: unsupported function func()")
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := rf.Fields(reflect.TypeOf(A{}))
+			as.Nil(err)
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+
+	var args struct {
+		UserID int `json:"user_id"`
+	}
+	err = rf.DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{"userId": 3}}, &args)
+	req.Nil(err)
+	as.Equal(3, args.UserID)
+}
+
+type Price float64
+
+type Point struct {
+	X int `json:"x"`
+}
+
+type Rectangle struct {
+	W int `json:"w"`
+}
+
+func TestTypeNames(t *testing.T) {
+	type Sub struct {
+		A string `json:"a"`
+	}
+	type S struct {
+		Sub     Sub             `json:"sub"`
+		Subs    []*Sub          `json:"subs"`
+		Anon    struct{}        `json:"anon"`
+		Map     map[string]int  `json:"map"`
+		Point   Point           `json:"point"`
+		ImPoint image.Point     `json:"im_point"`
+		ImPts   []image.Point   `json:"im_pts"`
+		AnonMap map[string]*Sub `json:"anon_map"`
+	}
+	as := assert.New(t)
+
+	names := func() map[string]string {
+		gqlt := ReflectTypeFq("root", reflect.TypeOf(S{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+		obj := gqlt.(*graphql.Object)
+		names := map[string]string{"": obj.Name()}
+		for name, f := range obj.Fields() {
+			names[name] = f.Type.String()
+		}
+		return names
+	}
+	expected := map[string]string{
+		"":         "S",
+		"sub":      "Sub",
+		"subs":     "[Sub]",
+		"anon":     "S_anon",
+		"map":      "[S_map_entry]",
+		"point":    "Point",
+		"im_point": "image_Point",
+		"im_pts":   "[image_Point]",
+		"anon_map": "[S_anon_map_entry]",
+	}
+	as.Equal(expected, names())
+	// Names do not depend on previous reflections
+	as.Equal(expected, names())
+}
+
+func TestTypeNamesOrder(t *testing.T) {
+	type IP struct {
+		I image.Point `json:"i"`
+		P Point       `json:"p"`
+	}
+	type PI struct {
+		P Point       `json:"p"`
+		I image.Point `json:"i"`
+	}
+	as := assert.New(t)
+
+	// Whichever is reflected first, Point of this package keeps its name
+	for _, instance := range []interface{}{IP{}, PI{}} {
+		fields := ReflectType(instance).(*graphql.Object).Fields()
+		as.Equal("image_Point", fields["i"].Type.Name())
+		as.Equal("Point", fields["p"].Type.Name())
+	}
+}
+
+func TestTypeNameCollision(t *testing.T) {
+	type A struct {
+		X string `json:"x"`
+	}
+	type B struct {
+		A A `json:"a"`
+	}
+	namer := func(t reflect.Type, name GqlName) GqlName {
+		return "Same"
+	}
+	assert.PanicsWithValue(t,
+		"GQL type name collision: both reflector.B and reflector.A are named Same",
+		func() {
+			ReflectTypeFq("b", reflect.TypeOf(B{}), GetDefaultTypeMap(),
+				ExcludeFieldTag(""), WithTypeNamer(namer))
+		})
+
+	// Types of the same name that are reflected separately share the default
+	// cache, so types of other packages are qualified, and types of the same
+	// package collide
+	rect := ReflectType(Rectangle{}, WithUntaggedFields(true))
+	imageRect := ReflectType(image.Rectangle{}, WithUntaggedFields(true))
+	assert.Equal(t, "Rectangle", rect.Name())
+	assert.Equal(t, "image_Rectangle", imageRect.Name())
+	_, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"rect":       &graphql.Field{Type: rect},
+				"image_rect": &graphql.Field{Type: imageRect},
+			},
+		}),
+	})
+	assert.Nil(t, err)
+
+	type Twin struct {
+		X string `json:"x"`
+	}
+	ReflectType(Twin{})
+	reflectOtherTwin := func() {
+		type Twin struct {
+			Y string `json:"y"`
+		}
+		ReflectType(Twin{})
+	}
+	assert.PanicsWithValue(t,
+		"GQL type name collision: two different types reflector.Twin are named Twin",
+		reflectOtherTwin)
+}
+
+func TestTypeCache(t *testing.T) {
+	type Shared struct {
+		X string `json:"x"`
+	}
+	type A struct {
+		S Shared `json:"s"`
+	}
+	type B struct {
+		S *Shared `json:"s"`
+	}
+	as := assert.New(t)
+	req := require.New(t)
+
+	cache := NewTypeCache()
+	a := ReflectType(A{}, WithTypeCache(cache))
+	b := ReflectType(B{}, WithTypeCache(cache))
+	as.Equal(
+		a.(*graphql.Object).Fields()["s"].Type,
+		b.(*graphql.Object).Fields()["s"].Type)
+
+	schema := func(a, b graphql.Type) error {
+		_, err := graphql.NewSchema(graphql.SchemaConfig{
+			Query: graphql.NewObject(graphql.ObjectConfig{
+				Name: "Query",
+				Fields: graphql.Fields{
+					"a": &graphql.Field{Type: a},
+					"b": &graphql.Field{Type: b},
+				},
+			}),
+		})
+		return err
+	}
+	req.Nil(schema(a, b))
+
+	// Without a cache, reflections of the same configuration share the
+	// default cache of the configuration
+	a = ReflectType(A{})
+	b = ReflectTypeFq("", reflect.TypeOf(B{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Equal(
+		a.(*graphql.Object).Fields()["s"].Type,
+		b.(*graphql.Object).Fields()["s"].Type)
+	req.Nil(schema(a, b))
+	a = ReflectType(A{}, WithNullability(InferNonNull))
+	as.NotEqual(
+		a.(*graphql.Object).Fields()["s"].Type,
+		b.(*graphql.Object).Fields()["s"].Type)
+
+	// Type maps whose resolvers are different closures of the same function
+	// do not share a default cache
+	type Price float64
+	type Order struct {
+		P Price `json:"p"`
+	}
+	currency := func(c string) TypeMap {
+		typeMap := GetDefaultTypeMap()
+		typeMap[reflect.TypeOf(Price(0))] = GqlOutputAndResolver{
+			Output: graphql.String,
+			Resolver: func(p graphql.ResolveParams) (interface{}, error) {
+				return c, nil
+			},
+		}
+		return typeMap
+	}
+	for _, c := range []string{"USD", "EUR"} {
+		f := graphql.Field{
+			Type: ReflectTypeWithTypeMap(Order{}, currency(c)),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return Order{}, nil
+			},
+		}
+		assertQuery(t, f, "order", "{p}", `{"data":{"order":{"p":"`+c+`"}}}`, "")
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	as := assert.New(t)
+	as.Equal(GqlName("Pair_int_y_Z"), sanitizeName("Pair[int,github.com/x/y.Z]"))
+	as.Equal(GqlName("Box_map_string_int"), sanitizeName("Box[map[string]int]"))
+	as.Equal(GqlName("github_com_x_go_y_Z"), sanitizeName("github.com/x/go-y.Z"))
+	as.Equal(GqlName("_1a"), sanitizeName("1a"))
+}
+
+func TestInputType(t *testing.T) {
+	type Sub struct {
+		X int `json:"x"`
+	}
+	type S struct {
+		A        string                 `json:"a"`
+		Sub      *Sub                   `json:"sub"`
+		Subs     []Sub                  `json:"subs"`
+		Map      map[string]int         `json:"map"`
+		Any      interface{}            `json:"any"`
+		JSON     map[string]interface{} `json:"json" gqlmap:"json"`
+		Ignored  string                 `json:"ignored" gqlexclude:"input"`
+		Required string                 `json:"required" gqlnull:"required"`
+	}
+	as := assert.New(t)
+	req := require.New(t)
+
+	cache := NewTypeCache()
+	input := ReflectInputTypeFq("s", reflect.TypeOf(S{}), GetDefaultTypeMap(),
+		ExcludeFieldTag("input"), WithTypeCache(cache))
+	obj, ok := input.(*graphql.InputObject)
+	req.True(ok)
+	as.Equal("SInput", obj.Name())
+	types := make(map[string]string)
+	for name, f := range obj.Fields() {
+		types[name] = f.Type.String()
+	}
+	as.Equal(map[string]string{
+		"a":        "String",
+		"sub":      "SubInput",
+		"subs":     "[SubInput]",
+		"map":      "[S_map_entryInput]",
+		"any":      "JSON",
+		"json":     "JSON",
+		"required": "String!",
+	}, types)
+
+	// The input and output types of the same struct may share a schema
+	f := graphql.Field{
+		Type: ReflectTypeFq("s", reflect.TypeOf(S{}), GetDefaultTypeMap(),
+			ExcludeFieldTag("input"), WithTypeCache(cache)),
+		Args: graphql.FieldConfigArgument{
+			"in": &graphql.ArgumentConfig{Type: input},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			in := p.Args["in"].(map[string]interface{})
+			as.Equal(map[string]interface{}{
+				"a":        "a",
+				"sub":      map[string]interface{}{"x": 1},
+				"map":      []interface{}{map[string]interface{}{"key": "k", "value": 2}},
+				"any":      []interface{}{int64(1), "b"},
+				"json":     map[string]interface{}{"c": true},
+				"required": "r",
+			}, in)
+			return S{A: in["a"].(string)}, nil
+		},
+	}
+	assertQuery(t, f, "s", `(in: {
+		a: "a"
+		sub: {x: 1}
+		map: [{key: "k", value: 2}]
+		any: [1, "b"]
+		json: {c: true}
+		required: "r"
+	}) {a}`, `{"data":{"s":{"a":"a"}}}`, "")
+	assertQuery(t, f, "s", `(in: {a: "a"}) {a}`, "",
+		`In field "required": Expected "String!", found null.`)
+}
+
+type enumStatus string
+
+const (
+	enumActive   enumStatus = "active"
+	enumInactive enumStatus = "in-active"
+)
+
+func (enumStatus) Values() []enumStatus {
+	return []enumStatus{enumActive, enumInactive}
+}
+
+type enumLevel int
+
+func (l enumLevel) String() string {
+	return [...]string{"LOW", "HIGH"}[l]
+}
+
+func TestEnums(t *testing.T) {
+	type S struct {
+		Status   enumStatus   `json:"status"`
+		Level    enumLevel    `json:"level"`
+		Levels   []enumLevel  `json:"levels"`
+		Pointer  *enumStatus  `json:"pointer"`
+		Invalid  enumStatus   `json:"invalid"`
+		Invalids []enumStatus `json:"invalids"`
+		Bad      enumLevel    `json:"bad"`
+	}
+	type Args struct {
+		Status enumStatus `json:"status"`
+		Level  enumLevel  `json:"level"`
+	}
+	as := assert.New(t)
+
+	typeMap := TypeMap{}
+	for goType, m := range GetDefaultTypeMap() {
+		typeMap[goType] = m
+	}
+	typeMap[reflect.TypeOf(enumLevel(0))] = NewEnum([]enumLevel{0, 1})
+
+	cache := NewTypeCache()
+	gqlt := ReflectTypeWithTypeMap(S{}, typeMap, WithTypeCache(cache))
+	fields := gqlt.(*graphql.Object).Fields()
+	as.Equal("enumStatus", fields["status"].Type.String())
+	as.Equal("enumLevel", fields["level"].Type.String())
+
+	var decoded Args
+	f := graphql.Field{
+		Type: gqlt,
+		Args: ReflectArgsFq(reflect.TypeOf(Args{}), typeMap, ExcludeFieldTag(""), WithTypeCache(cache)),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if err := DecodeArgs(p, &decoded); err != nil {
+				return nil, err
+			}
+			status := enumInactive
+			return S{
+				Status:   decoded.Status,
+				Level:    decoded.Level,
+				Levels:   []enumLevel{1, 0},
+				Pointer:  &status,
+				Invalid:  "nope",
+				Invalids: []enumStatus{enumActive, "nope"},
+				Bad:      5,
+			}, nil
+		},
+	}
+	assertQuery(t, f, "s", `(status: in_active, level: HIGH) {
+		status
+		level
+		levels
+		pointer
+	}`, `{"data":{"s":{
+		"status": "in_active",
+		"level": "HIGH",
+		"levels": ["HIGH", "LOW"],
+		"pointer": "in_active"
+	}}}`, "")
+	as.Equal(Args{Status: enumInactive, Level: 1}, decoded)
+	// Values that are not among the values of their enum fail to resolve
+	assertQuery(t, f, "s", `{invalid}`, "",
+		`invalid value "nope" of enum reflector.enumStatus`)
+	assertQuery(t, f, "s", `{invalids}`, "",
+		`invalid value "nope" of enum reflector.enumStatus`)
+	assertQuery(t, f, "s", `{bad}`, "",
+		`invalid value 5 of enum reflector.enumLevel`)
+	assertQuery(t, f, "s", `(status: gone) {status}`, "",
+		`Argument "status" has invalid value gone`)
+}
+
 // runs a graphql query and asserts the result
 // in case there query should result in an error then set the expectedError
 // argument to non-empty string. This string should be a substript  of the
@@ -362,3 +1345,555 @@ func assertQuery(
 	req.Nil(err)
 	assert.JSONEq(t, expectedResult, string(result))
 }
+
+func TestTypeMaps(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+	type Money int64
+	type Code string
+	moneyType, codeType := reflect.TypeOf(Money(0)), reflect.TypeOf(Code(""))
+	money := GqlOutputAndResolver{Output: graphql.Float, Resolver: trivialResolver}
+	moneyAsString := GqlOutputAndResolver{Output: graphql.String, Resolver: trivialResolver}
+	code := GqlOutputAndResolver{Output: graphql.ID, Resolver: trivialResolver}
+
+	clone := GetDefaultTypeMap().Clone()
+	clone[moneyType] = money
+	as.NotContains(GetDefaultTypeMap(), moneyType)
+
+	// Merging the same mapping twice is no conflict
+	merged, err := GetDefaultTypeMap().Merge(TypeMap{moneyType: money}, TypeMap{moneyType: money, codeType: code})
+	req.Nil(err)
+	as.Equal(graphql.Float, merged[moneyType].Output)
+	as.Equal(graphql.ID, merged[codeType].Output)
+
+	merged, err = TypeMap{moneyType: money}.Merge(TypeMap{moneyType: moneyAsString})
+	req.NotNil(err)
+	as.Equal(graphql.Float, merged[moneyType].Output)
+	as.Equal([]reflect.Type{moneyType}, err.(*TypeMapConflictError).Types)
+	as.Contains(err.Error(), "conflicting type map registrations of reflector.Money")
+
+	// Resolvers made by the same function are different registrations
+	currency := func(c string) GqlOutputAndResolver {
+		return GqlOutputAndResolver{
+			Output: graphql.String,
+			Resolver: func(p graphql.ResolveParams) (interface{}, error) {
+				return c, nil
+			},
+		}
+	}
+	_, err = TypeMap{moneyType: currency("USD")}.Merge(TypeMap{moneyType: currency("EUR")})
+	as.NotNil(err)
+	usd := currency("USD")
+	_, err = TypeMap{moneyType: usd}.Merge(TypeMap{moneyType: usd})
+	as.Nil(err)
+
+	overridden := TypeMap{moneyType: money}.Override(TypeMap{moneyType: moneyAsString})
+	as.Equal(graphql.String, overridden[moneyType].Output)
+
+	base := NewTypeMapBuilder(GetDefaultTypeMap()).Register(Money(0), money)
+	conflicting := base.Register(Money(0), moneyAsString).Register(Code(""), code)
+	// Builders are immutable
+	typeMap, err := base.Build()
+	req.Nil(err)
+	as.NotContains(typeMap, codeType)
+	typeMap, err = conflicting.Build()
+	req.NotNil(err)
+	as.Contains(typeMap, codeType)
+	typeMap, err = conflicting.Override(TypeMap{moneyType: moneyAsString}).Build()
+	req.Nil(err)
+	as.Equal(graphql.String, typeMap[moneyType].Output)
+	_, err = NewTypeMapBuilder(nil).Register(time.Time{}, money).Merge(GetDefaultTypeMap()).Build()
+	as.Contains(err.Error(), "time.Time")
+
+	type S struct {
+		M Money `json:"m"`
+		C Code  `json:"c"`
+	}
+	fields := ReflectFieldsFq(reflect.TypeOf(S{}), TypeMap{moneyType: money}, ExcludeFieldTag(""),
+		WithTypeMapLayer(TypeMap{codeType: code}), WithTypeMapLayer(TypeMap{moneyType: moneyAsString}))
+	as.Equal(graphql.String, fields["m"].Type)
+	as.Equal(graphql.ID, fields["c"].Type)
+	gqlt, err := New(WithTypeMapLayer(TypeMap{moneyType: money})).Type(S{})
+	req.Nil(err)
+	objFields := gqlt.(*graphql.Object).Fields()
+	as.Equal(graphql.Float, objFields["m"].Type)
+	as.Equal(graphql.String, objFields["c"].Type)
+}
+
+func TestTypeMapResolverSource(t *testing.T) {
+	type Amount int
+	type Order struct {
+		Amount   Amount `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	// Formats amounts in the currency of their order, if they have one
+	typeMap := GetDefaultTypeMap()
+	typeMap[reflect.TypeOf(Amount(0))] = GqlOutputAndResolver{
+		Output: graphql.String,
+		Resolver: func(p graphql.ResolveParams) (interface{}, error) {
+			amount := GetValueFromResolveParams(p).Int()
+			if order, ok := p.Source.(Order); ok {
+				return fmt.Sprintf("%d %s", amount, order.Currency), nil
+			}
+			return fmt.Sprint(amount), nil
+		},
+	}
+	f := graphql.Field{
+		Type: ReflectTypeWithTypeMap(Order{}, typeMap),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return Order{Amount: 3, Currency: "EUR"}, nil
+		},
+	}
+	assertQuery(t, f, "order", "{amount}", `{"data":{"order":{"amount":"3 EUR"}}}`, "")
+
+	f = *ReflectFuncFq("amount", func() Amount { return 5 }, typeMap, ExcludeFieldTag(""))
+	assertQuery(t, f, "amount", "", `{"data":{"amount":"5"}}`, "")
+}
+
+// A struct valuer with a pointer receiver, stored as a string
+type sqlPoint struct {
+	x, y int
+}
+
+func (p *sqlPoint) Value() (driver.Value, error) {
+	return fmt.Sprintf("(%d,%d)", p.x, p.y), nil
+}
+
+func (p *sqlPoint) Scan(src interface{}) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a point", src)
+	}
+	_, err := fmt.Sscanf(s, "(%d,%d)", &p.x, &p.y)
+	return err
+}
+
+// A struct valuer with fields of its own, which is reflected as an object
+type sqlAddress struct {
+	City string `json:"city"`
+}
+
+func (a sqlAddress) Value() (driver.Value, error) {
+	return a.City, nil
+}
+
+func TestSQLTypes(t *testing.T) {
+	as := assert.New(t)
+	type Row struct {
+		Name    sql.NullString  `json:"name"`
+		Count   sql.NullInt64   `json:"count"`
+		Small   sql.NullInt32   `json:"small"`
+		Ratio   sql.NullFloat64 `json:"ratio"`
+		Active  sql.NullBool    `json:"active"`
+		Updated sql.NullTime    `json:"updated"`
+		Point   sqlPoint        `json:"point"`
+		Origin  *sqlPoint       `json:"origin"`
+		Address sqlAddress      `json:"address"`
+	}
+	type Args struct {
+		Name    sql.NullString `json:"name"`
+		Small   sql.NullInt32  `json:"small"`
+		Updated sql.NullTime   `json:"updated"`
+		Point   sqlPoint       `json:"point"`
+	}
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	f := ReflectFuncFq("row", func(args Args) Row {
+		return Row{
+			Name:    args.Name,
+			Count:   sql.NullInt64{Int64: 5, Valid: true},
+			Small:   args.Small,
+			Ratio:   sql.NullFloat64{Float64: 0.5, Valid: true},
+			Updated: args.Updated,
+			Point:   args.Point,
+		}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull))
+	fields := f.Type.(*graphql.NonNull).OfType.(*graphql.Object).Fields()
+	as.Equal("String", fields["name"].Type.String())
+	as.Equal("Int", fields["small"].Type.String())
+	as.Equal("Boolean", fields["active"].Type.String())
+	as.Equal("JSON", fields["point"].Type.String())
+	as.Equal("sqlAddress!", fields["address"].Type.String())
+	as.Equal("DateTime", fields["updated"].Type.String())
+	as.Equal("DateTime", f.Args["updated"].Type.String())
+	as.Equal("JSON", f.Args["point"].Type.String())
+
+	assertQuery(t, *f, "row",
+		`(name: "a", small: 3, updated: "2009-11-10T23:00:00Z", point: "(1,2)")
+			{name count small ratio active updated point origin}`,
+		`{"data":{"row":{"name":"a","count":5,"small":3,"ratio":0.5,"active":null,
+			"updated":"2009-11-10T23:00:00Z","point":"(1,2)","origin":null}}}`, "")
+	assertQuery(t, *f, "row", `{name small updated point}`,
+		`{"data":{"row":{"name":null,"small":null,"updated":null,"point":"(0,0)"}}}`, "")
+	assertQuery(t, *f, "row", `(point: 5) {point}`, "", `cannot decode argument "point": cannot scan int64 into a point`)
+	assertQuery(t, *f, "row", `(updated: "yesterday") {updated}`, "", `Expected type "DateTime"`)
+
+	var args Args
+	err := DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{
+		"name": "b", "small": 7, "updated": "2009-11-10T23:00:00Z",
+	}}, &args)
+	as.Nil(err)
+	as.Equal(sql.NullString{String: "b", Valid: true}, args.Name)
+	as.Equal(sql.NullInt32{Int32: 7, Valid: true}, args.Small)
+	as.Equal(sql.NullTime{Time: tm, Valid: true}, args.Updated)
+}
+
+func TestTimeTypes(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+	type Event struct {
+		At       time.Time     `json:"at"`
+		Day      time.Time     `json:"day" gqltime:"date,tz=America/New_York"`
+		Local    *time.Time    `json:"local" gqltime:"02/01/2006 15:04,tz=Asia/Tokyo"`
+		Never    time.Time     `json:"never"`
+		Duration time.Duration `json:"duration"`
+	}
+	type Args struct {
+		At       time.Time      `json:"at"`
+		Day      time.Time      `json:"day" gqltime:"date,tz=America/New_York"`
+		Local    *time.Time     `json:"local" gqltime:"02/01/2006 15:04,tz=Asia/Tokyo"`
+		Duration time.Duration  `json:"duration" gqldefault:"1h30m" gqlnull:"nullable"`
+		Timeout  *time.Duration `json:"timeout"`
+	}
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	var decoded Args
+	f := ReflectFuncFq("event", func(args Args) Event {
+		decoded = args
+		return Event{At: tm, Day: tm, Local: &tm, Duration: args.Duration}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull))
+	fields := f.Type.(*graphql.NonNull).OfType.(*graphql.Object).Fields()
+	as.Equal("DateTime!", fields["at"].Type.String())
+	as.Equal("Date!", fields["day"].Type.String())
+	as.Equal("String", fields["local"].Type.String())
+	as.Equal("Duration!", fields["duration"].Type.String())
+	as.Equal("Date!", f.Args["day"].Type.String())
+	as.Equal("1h30m", f.Args["duration"].DefaultValue)
+
+	assertQuery(t, *f, "event",
+		`(at: "2009-11-10T23:00:00Z", day: "2009-11-10", local: "11/11/2009 08:00", timeout: 5)
+			{at day local never duration}`,
+		`{"data":{"event":{"at":"2009-11-10T23:00:00Z","day":"2009-11-10","local":"11/11/2009 08:00",
+			"never":"0001-01-01T00:00:00Z","duration":"1h30m0s"}}}`, "")
+	as.Equal(tm, decoded.At)
+	newYork, err := time.LoadLocation("America/New_York")
+	req.Nil(err)
+	as.True(decoded.Day.Equal(time.Date(2009, time.November, 10, 0, 0, 0, 0, newYork)))
+	as.True(decoded.Local.Equal(tm))
+	as.Equal(90*time.Minute, decoded.Duration)
+	as.Equal(5*time.Nanosecond, *decoded.Timeout)
+	assertQuery(t, *f, "event", `(at: "yesterday") {at}`, "", `Expected type "DateTime"`)
+
+	f = ReflectFuncFq("event", func() Event {
+		return Event{At: tm, Day: tm}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithNullability(InferNonNull),
+		WithZeroTimeAsNull(true), WithTimeFormatArg(true))
+	fields = f.Type.(*graphql.NonNull).OfType.(*graphql.Object).Fields()
+	as.Equal("DateTime", fields["never"].Type.String())
+	as.Len(fields["at"].Args, 1)
+	as.Empty(fields["day"].Args)
+	assertQuery(t, *f, "event", `{at(format: DATE) never}`,
+		`{"data":{"event":{"at":"2009-11-10","never":null}}}`, "")
+
+	var args Args
+	err = DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{
+		"duration": "2s", "day": "2009-11-10",
+	}}, &args)
+	req.Nil(err)
+	as.Equal(2*time.Second, args.Duration)
+	as.Equal(newYork, args.Day.Location())
+	as.NotNil(DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{
+		"duration": "soon",
+	}}, &args))
+
+	_, err = ReflectTypeFqE("", reflect.TypeOf(struct {
+		At time.Time `json:"at" gqltime:"date,tz=Nowhere/Special"`
+	}{}), GetDefaultTypeMap(), ExcludeFieldTag(""))
+	as.Contains(err.Error(), "Invalid time zone of At")
+}
+
+func TestNumbers(t *testing.T) {
+	as := assert.New(t)
+	req := require.New(t)
+	type Counters struct {
+		ID     int64     `json:"id" gqlint:"int64"`
+		Hits   uint64    `json:"hits" gqlint:"uint64"`
+		IDs    []int64   `json:"ids" gqlint:"int64"`
+		Small  *int32    `json:"small" gqlint:"int"`
+		Total  big.Int   `json:"total"`
+		Share  *big.Rat  `json:"share"`
+		Weight big.Float `json:"weight"`
+	}
+	type Args struct {
+		ID    int64    `json:"id" gqlint:"int64"`
+		Hits  uint64   `json:"hits" gqlint:"uint64"`
+		Total *big.Int `json:"total"`
+		Share big.Rat  `json:"share"`
+	}
+	var decoded Args
+	f := ReflectFuncFq("counters", func(args Args) Counters {
+		decoded = args
+		c := Counters{
+			ID:    args.ID,
+			Hits:  args.Hits,
+			IDs:   []int64{math.MinInt64},
+			Share: &args.Share,
+		}
+		if args.Total != nil {
+			c.Total.Set(args.Total)
+		}
+		c.Weight.SetFloat64(0.25)
+		return c
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	fields := f.Type.(*graphql.Object).Fields()
+	as.Equal("Int64", fields["id"].Type.String())
+	as.Equal("UInt64", fields["hits"].Type.String())
+	as.Equal("[Int64]", fields["ids"].Type.String())
+	as.Equal("Int", fields["small"].Type.String())
+	as.Equal("BigInt", fields["total"].Type.String())
+	as.Equal("UInt64", f.Args["hits"].Type.String())
+
+	assertQuery(t, *f, "counters",
+		`(id: "9223372036854775807", hits: 18446744073709551615,
+			total: "123456789012345678901234567890", share: "1/3")
+			{id hits ids small total share weight}`,
+		`{"data":{"counters":{"id":"9223372036854775807","hits":"18446744073709551615",
+			"ids":["-9223372036854775808"],"small":null,"total":"123456789012345678901234567890",
+			"share":"1/3","weight":"0.25"}}}`, "")
+	as.Equal(int64(math.MaxInt64), decoded.ID)
+	as.Equal(uint64(math.MaxUint64), decoded.Hits)
+	as.Equal("1/3", decoded.Share.RatString())
+	assertQuery(t, *f, "counters", `(id: "12x") {id}`, "", `Expected type "Int64"`)
+
+	var args Args
+	req.Nil(DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{
+		"id": "-5", "hits": 7, "total": "42", "share": 0.5,
+	}}, &args))
+	as.Equal(int64(-5), args.ID)
+	as.Equal(uint64(7), args.Hits)
+	as.Equal("42", args.Total.String())
+	as.Equal("1/2", args.Share.RatString())
+
+	type Wide struct {
+		Int64  int64  `json:"int_64"`
+		Uint32 uint32 `json:"uint_32"`
+		Int16  int16  `json:"int_16"`
+	}
+	fields = ReflectType(Wide{}, WithTypeMapLayer(Int64TypeMap())).(*graphql.Object).Fields()
+	as.Equal("Int64", fields["int_64"].Type.String())
+	as.Equal("UInt64", fields["uint_32"].Type.String())
+	as.Equal("Int", fields["int_16"].Type.String())
+
+	_, err := ReflectTypeFqE("", reflect.TypeOf(Wide{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithStrictIntegers(true))
+	req.NotNil(err)
+	as.Len(err.(ReflectionErrors), 2)
+	as.Contains(err.Error(), "Wide.Int64 (int64): int64 does not fit in Int")
+	as.Contains(err.Error(), "Wide.Uint32 (uint32): uint32 does not fit in Int")
+	_, err = ReflectTypeFqE("", reflect.TypeOf(struct {
+		Hits uint64 `json:"hits" gqlint:"int64"`
+	}{}), GetDefaultTypeMap(), ExcludeFieldTag(""), WithStrictIntegers(true))
+	as.Contains(err.Error(), "uint64 does not fit in Int64")
+	_, err = ReflectTypeFqE("", reflect.TypeOf(Wide{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithStrictIntegers(true), WithTypeMapLayer(Int64TypeMap()))
+	as.Nil(err)
+	as.Panics(func() {
+		ReflectType(Wide{}, WithStrictIntegers(true))
+	})
+
+	type Big struct {
+		Wide
+		Counts []uint32 `json:"counts"`
+	}
+	var large Big
+	f = ReflectFuncFq("big", func() Big { return large }, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	large.Int64, large.Counts = math.MinInt32, []uint32{math.MaxInt32}
+	assertQuery(t, *f, "big", "{int_64 counts}",
+		`{"data":{"big":{"int_64":-2147483648,"counts":[2147483647]}}}`, "")
+	large.Int64 = 1 << 40
+	assertQuery(t, *f, "big", "{int_64}", "",
+		"1099511627776 does not fit in Int, use a gqlint tag or Int64TypeMap")
+	large.Counts = []uint32{1, math.MaxUint32}
+	assertQuery(t, *f, "big", "{counts}", "", "4294967295 does not fit in Int")
+	f = ReflectFuncFq("wide", func() int64 { return 1 << 40 }, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	assertQuery(t, *f, "wide", "", "", "1099511627776 does not fit in Int")
+}
+
+// A UUID that marshals itself into text
+type textID [4]byte
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+func (id *textID) UnmarshalText(text []byte) error {
+	_, err := hex.Decode(id[:], text)
+	return err
+}
+
+// A struct that marshals itself into JSON, with a pointer receiver
+type jsonRange struct {
+	from, to int
+}
+
+func (r *jsonRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{r.from, r.to})
+}
+
+func (r *jsonRange) UnmarshalJSON(data []byte) error {
+	var bounds []int
+	if err := json.Unmarshal(data, &bounds); err != nil {
+		return err
+	}
+	if len(bounds) != 2 {
+		return fmt.Errorf("a range needs 2 bounds, got %d", len(bounds))
+	}
+	r.from, r.to = bounds[0], bounds[1]
+	return nil
+}
+
+// Structs with fields of their own, which are reflected as objects even
+// though they marshal themselves
+type namedPoint struct {
+	Name string `json:"name"`
+}
+
+func (p namedPoint) String() string {
+	return p.Name
+}
+
+type jsonUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (u jsonUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Name + " <" + u.Email + ">")
+}
+
+type textUser struct {
+	Name string `json:"name"`
+}
+
+func (u textUser) MarshalText() ([]byte, error) {
+	return []byte(u.Name), nil
+}
+
+// A UUID that is an sql valuer and scanner as well as a text marshaler, as
+// most UUID types are
+type valuerUUID [4]byte
+
+func (u valuerUUID) Value() (driver.Value, error) {
+	return u[:], nil
+}
+
+func (u *valuerUUID) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok || len(b) != len(u) {
+		return fmt.Errorf("cannot scan %T into a UUID", src)
+	}
+	copy(u[:], b)
+	return nil
+}
+
+func (u valuerUUID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(u[:])), nil
+}
+
+func (u *valuerUUID) UnmarshalText(text []byte) error {
+	_, err := hex.Decode(u[:], text)
+	return err
+}
+
+// A struct with untagged exported fields that marshals into text
+type textVersion struct {
+	Major, Minor int
+}
+
+func (v textVersion) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d", v.Major, v.Minor)), nil
+}
+
+func TestMarshalers(t *testing.T) {
+	as := assert.New(t)
+	type Host struct {
+		IP      net.IP      `json:"ip"`
+		ID      textID      `json:"id"`
+		IDs     []textID    `json:"ids"`
+		Range   jsonRange   `json:"range"`
+		Point   namedPoint  `json:"point"`
+		User    jsonUser    `json:"user"`
+		Owner   textUser    `json:"owner"`
+		Seen    time.Time   `json:"seen"`
+		URL     url.URL     `json:"url"`
+		Link    *url.URL    `json:"link"`
+		UUID    valuerUUID  `json:"uuid"`
+		Version textVersion `json:"version"`
+	}
+	type Args struct {
+		IP    net.IP     `json:"ip"`
+		ID    *textID    `json:"id"`
+		IDs   []textID   `json:"ids"`
+		Range jsonRange  `json:"range"`
+		URL   *url.URL   `json:"url"`
+		UUID  valuerUUID `json:"uuid"`
+	}
+	tm := time.Date(2009, time.November, 10, 23, 0, 0, 5, time.UTC)
+	var decoded Args
+	f := ReflectFuncFq("host", func(args Args) Host {
+		decoded = args
+		return Host{
+			IP: args.IP, IDs: args.IDs, Range: args.Range, Point: namedPoint{Name: "p"},
+			User: jsonUser{Name: "u", Email: "u@example.com"}, Owner: textUser{Name: "o"},
+			Seen: tm, URL: *args.URL, Link: args.URL, UUID: args.UUID,
+			Version: textVersion{Major: 1, Minor: 2},
+		}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""))
+	fields := f.Type.(*graphql.Object).Fields()
+	as.Equal("String", fields["ip"].Type.String())
+	as.Equal("String", fields["id"].Type.String())
+	as.Equal("[String]", fields["ids"].Type.String())
+	as.Equal("JSON", fields["range"].Type.String())
+	as.Equal("namedPoint", fields["point"].Type.String())
+	as.Equal("jsonUser", fields["user"].Type.String())
+	as.Equal("textUser", fields["owner"].Type.String())
+	as.Equal("DateTime", fields["seen"].Type.String())
+	as.Equal("String", fields["url"].Type.String())
+	as.Equal("String", fields["link"].Type.String())
+	as.Equal("String", fields["uuid"].Type.String())
+	as.Equal("String", fields["version"].Type.String())
+	as.Equal("String", f.Args["id"].Type.String())
+	as.Equal("JSON", f.Args["range"].Type.String())
+	as.Equal("String", f.Args["url"].Type.String())
+	as.Equal("String", f.Args["uuid"].Type.String())
+
+	assertQuery(t, *f, "host",
+		`(ip: "10.0.0.1", id: "0a0b0c0d", ids: ["01020304"], range: [1, 5],
+			url: "https://example.com/a?b=c", uuid: "01020304")
+			{ip id ids range point {name} user {name email} owner {name} seen
+				url link uuid version}`,
+		`{"data":{"host":{"ip":"10.0.0.1","id":"00000000","ids":["01020304"],
+			"range":[1,5],"point":{"name":"p"},"user":{"name":"u","email":"u@example.com"},
+			"owner":{"name":"o"},"seen":"2009-11-10T23:00:00Z",
+			"url":"https://example.com/a?b=c","link":"https://example.com/a?b=c",
+			"uuid":"01020304","version":"1.2"}}}`, "")
+	as.Equal(net.ParseIP("10.0.0.1"), decoded.IP)
+	as.Equal(&textID{10, 11, 12, 13}, decoded.ID)
+	as.Equal(jsonRange{1, 5}, decoded.Range)
+	as.Equal("example.com", decoded.URL.Host)
+	as.Equal(valuerUUID{1, 2, 3, 4}, decoded.UUID)
+	assertQuery(t, *f, "host", `(range: [1]) {ip}`, "", "a range needs 2 bounds, got 1")
+
+	var args Args
+	as.NotNil(DecodeArgs(graphql.ResolveParams{Args: map[string]interface{}{"id": "xyz"}}, &args))
+
+	// The type map may give way to marshalers, or marshalers may be ignored
+	fields = ReflectTypeFq("", reflect.TypeOf(Host{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithMarshalers(MarshalersBeforeTypeMap)).(*graphql.Object).Fields()
+	as.Equal("String", fields["seen"].Type.String())
+	f = ReflectFuncFq("host", func() Host {
+		return Host{Seen: tm}
+	}, GetDefaultTypeMap(), ExcludeFieldTag(""), WithMarshalers(MarshalersBeforeTypeMap))
+	assertQuery(t, *f, "host", `{seen}`, `{"data":{"host":{"seen":"2009-11-10T23:00:00.000000005Z"}}}`, "")
+	fields = ReflectTypeFq("", reflect.TypeOf(Host{}), GetDefaultTypeMap(), ExcludeFieldTag(""),
+		WithMarshalers(IgnoreMarshalers)).(*graphql.Object).Fields()
+	as.Equal("[Int]", fields["id"].Type.String())
+}
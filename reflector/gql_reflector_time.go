@@ -0,0 +1,293 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// DateLayout is the go time layout of Date values
+const DateLayout = "2006-01-02"
+
+// Values of the gqltime struct field tag, which sets how time.Time fields are
+// formatted and parsed. Any other value is a go time layout, such as
+// "02/01/2006 15:04", and the field is reflected as a String.
+// The tz option sets the time zone that times are formatted in, and parsed in
+// when they have none, for example `gqltime:"date,tz=Europe/Paris"`. The
+// default time zone is UTC.
+const (
+	// GqlTimeDateTime reflects a time field as a DateTime. This is the
+	// default.
+	GqlTimeDateTime = "datetime"
+	// GqlTimeDate reflects a time field as a Date
+	GqlTimeDate = "date"
+)
+
+// DateTime is a graphql scalar of points in time, formatted in RFC3339.
+// It's the type of time.Time in the default type map.
+var DateTime = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "DateTime",
+	Description: "The `DateTime` scalar type represents a point in time, " +
+		"formatted in RFC 3339, such as `2009-11-10T23:00:00Z`.",
+	Serialize: func(value interface{}) interface{} {
+		return serializeTime(value, time.RFC3339)
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return parseTime(value, time.RFC3339)
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if s, ok := valueAST.(*ast.StringValue); ok {
+			return parseTime(s.Value, time.RFC3339)
+		}
+		return nil
+	},
+})
+
+// Date is a graphql scalar of calendar dates, such as 2009-11-10
+var Date = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Date",
+	Description: "The `Date` scalar type represents a calendar date, " +
+		"such as `2009-11-10`.",
+	Serialize: func(value interface{}) interface{} {
+		return serializeTime(value, DateLayout)
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return parseTime(value, DateLayout)
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if s, ok := valueAST.(*ast.StringValue); ok {
+			return parseTime(s.Value, DateLayout)
+		}
+		return nil
+	},
+})
+
+// Duration is a graphql scalar of spans of time, formatted as go durations,
+// such as 1h30m0s. Input values may also be given in nanoseconds.
+// It's the type of time.Duration in the default type map.
+var Duration = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Duration",
+	Description: "The `Duration` scalar type represents a span of time, " +
+		"formatted as a go duration, such as `1h30m0s`. " +
+		"Input values may also be given in nanoseconds.",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case time.Duration:
+			return value.String()
+		case *time.Duration:
+			if value == nil {
+				return nil
+			}
+			return value.String()
+		}
+		// Such as a duration that was formatted by its resolver
+		return value
+	},
+	ParseValue: parseDuration,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch valueAST := valueAST.(type) {
+		case *ast.StringValue:
+			return parseDuration(valueAST.Value)
+		case *ast.IntValue:
+			if i, err := strconv.ParseInt(valueAST.Value, 10, 64); err == nil {
+				return time.Duration(i)
+			}
+		}
+		return nil
+	},
+})
+
+// TimeFormat is the enum of the format argument of time fields, when they are
+// reflected with one (see WithTimeFormatArg). Its values are go time layouts.
+var TimeFormat = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "TimeFormat",
+	Description: "The formats of time fields",
+	Values: graphql.EnumValueConfigMap{
+		"RFC3339": {
+			Value:       time.RFC3339,
+			Description: "RFC 3339, such as `2009-11-10T23:00:00Z`",
+		},
+		"RFC3339_NANO": {
+			Value:       time.RFC3339Nano,
+			Description: "RFC 3339 with nanoseconds, such as `2009-11-10T23:00:00.5Z`",
+		},
+		"DATE": {
+			Value:       DateLayout,
+			Description: "The date alone, such as `2009-11-10`",
+		},
+	},
+})
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Format value, a time.Time or a pointer to one, by layout. Anything else is
+// taken as already formatted, for example by its resolver.
+func serializeTime(value interface{}, layout string) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		return value.Format(layout)
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return value.Format(layout)
+	}
+	return value
+}
+
+// Parse value, a string formatted by layout, into a time.Time. Returns nil if
+// value is not such a string, which graphql reports as an invalid value.
+func parseTime(value interface{}, layout string) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		return value
+	case string:
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return nil
+		}
+		return t
+	}
+	return nil
+}
+
+// Parse value, a go duration string or a number of nanoseconds, into a
+// time.Duration. Returns nil if value is neither.
+func parseDuration(value interface{}) interface{} {
+	switch value := value.(type) {
+	case time.Duration:
+		return value
+	case string:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil
+		}
+		return d
+	}
+	if i, ok := toInt64(reflect.ValueOf(value)); ok {
+		return time.Duration(i)
+	}
+	return nil
+}
+
+// timeFormat is the way a time.Time field is formatted and parsed, as set by
+// its gqltime tag
+type timeFormat struct {
+	// DateTime, Date or String for other layouts
+	scalar *graphql.Scalar
+	layout string
+	loc    *time.Location
+}
+
+// Whether the struct field f is a time field that's formatted by its own
+// rules, rather than by the type map: either it has a gqltime tag, or the
+// reflection formats all time fields (see WithZeroTimeAsNull and
+// WithTimeFormatArg)
+func (r *reflection) isTimeField(f structField) bool {
+	if indirectType(f.Type) != timeType {
+		return false
+	}
+	_, tagged := f.Tag.Lookup(GqlTimeTagName)
+	return tagged || r.zeroTimeAsNull || r.timeFormatArg
+}
+
+// Get the format of the time field f by its gqltime tag
+func getTimeFormat(f reflect.StructField) (timeFormat, error) {
+	format := timeFormat{scalar: DateTime, layout: time.RFC3339, loc: time.UTC}
+	tag, exists := f.Tag.Lookup(GqlTimeTagName)
+	if !exists {
+		return format, nil
+	}
+	options := strings.Split(tag, ",")
+	switch name := strings.Trim(options[0], " "); name {
+	case "", GqlTimeDateTime:
+	case GqlTimeDate:
+		format.scalar, format.layout = Date, DateLayout
+	default:
+		format.scalar, format.layout = graphql.String, name
+	}
+	for _, option := range options[1:] {
+		option = strings.Trim(option, " ")
+		if !strings.HasPrefix(option, "tz=") {
+			continue
+		}
+		loc, err := time.LoadLocation(strings.TrimPrefix(option, "tz="))
+		if err != nil {
+			return format, fmt.Errorf("Invalid time zone of %s: %s", f.Name, err)
+		}
+		format.loc = loc
+	}
+	return format, nil
+}
+
+// Reflect the time field f by its format
+func (r *reflection) reflectTimeField(f structField) *graphql.Field {
+	format, err := getTimeFormat(f.StructField)
+	if err != nil {
+		r.fail(f.Type, "%s", err)
+	}
+	field := &graphql.Field{
+		Name:    string(f.name),
+		Type:    format.scalar,
+		Resolve: timeFormatResolver(format, r.zeroTimeAsNull),
+	}
+	if r.timeFormatArg && format.scalar == DateTime {
+		field.Args = graphql.FieldConfigArgument{
+			"format": &graphql.ArgumentConfig{
+				Type:        TimeFormat,
+				Description: "The format of the time, RFC3339 by default",
+			},
+		}
+	}
+	return field
+}
+
+// Resolves time fields by formatting them in format, or by the layout of
+// their format argument, if given. Zero times resolve to null if zeroAsNull.
+func timeFormatResolver(format timeFormat, zeroAsNull bool) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		value := GetValueFromResolveParams(p)
+		if !value.IsValid() || value.Kind() == reflect.Ptr {
+			// Only nil pointers are not dereferenced
+			return nil, nil
+		}
+		t, ok := value.Interface().(time.Time)
+		if !ok {
+			// Such as a string held by a map source
+			return value.Interface(), nil
+		}
+		if zeroAsNull && t.IsZero() {
+			return nil, nil
+		}
+		layout := format.layout
+		if arg, ok := p.Args["format"].(string); ok {
+			layout = arg
+		}
+		return t.In(format.loc).Format(layout), nil
+	}
+}
+
+// Decode src, the value of a time field of the given format, into dst.
+// Strings are parsed by the layout of format, in its time zone.
+func (d decoder) decodeTime(path string, src interface{}, dst reflect.Value, format timeFormat) error {
+	switch value := src.(type) {
+	case string:
+		t, err := time.ParseInLocation(format.layout, value, format.loc)
+		if err != nil {
+			return fmt.Errorf("cannot decode argument %q: %s", path, err)
+		}
+		src = t
+	case time.Time:
+		if format.scalar == Date {
+			// Dates are parsed in UTC
+			y, m, day := value.Date()
+			src = time.Date(y, m, day, 0, 0, 0, 0, format.loc)
+		}
+	}
+	return d.decodeValue(path, src, dst)
+}
@@ -0,0 +1,168 @@
+package reflector
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Clone returns a copy of the type map, which may be changed without
+// affecting m.
+func (m TypeMap) Clone() TypeMap {
+	clone := make(TypeMap, len(m))
+	for t, mapping := range m {
+		clone[t] = mapping
+	}
+	return clone
+}
+
+// Merge returns a new type map holding the mappings of m and all of others.
+// A go type that two of the maps map differently is a conflict: the result
+// keeps the mapping of the earlier map, and is returned along with a
+// *TypeMapConflictError. Mappings are the same only if they have the same
+// Output, Input and Resolver, which is the same function value: two closures
+// made by the same function are different resolvers.
+func (m TypeMap) Merge(others ...TypeMap) (TypeMap, error) {
+	return NewTypeMapBuilder(m).merge(others).Build()
+}
+
+// Override returns a new type map holding the mappings of m and all of
+// others, where the mappings of later maps take precedence over those of
+// earlier maps, for example
+//
+//	typeMap := GetDefaultTypeMap().Override(teamTypeMap)
+func (m TypeMap) Override(others ...TypeMap) TypeMap {
+	result := m.Clone()
+	for _, other := range others {
+		for t, mapping := range other {
+			result[t] = mapping
+		}
+	}
+	return result
+}
+
+// TypeMapConflictError is returned when type maps that are merged map the
+// same go types differently
+type TypeMapConflictError struct {
+	// Types are the conflicting go types, sorted by name
+	Types []reflect.Type
+}
+
+func (e *TypeMapConflictError) Error() string {
+	names := make([]string, len(e.Types))
+	for i, t := range e.Types {
+		names[i] = t.String()
+	}
+	return fmt.Sprintf("conflicting type map registrations of %s", strings.Join(names, ", "))
+}
+
+// TypeMapBuilder builds a type map layer by layer, for example the default
+// type map, the mappings registered by shared libraries and finally the
+// mappings of a team that override all others:
+//
+//	typeMap, err := NewTypeMapBuilder(GetDefaultTypeMap()).
+//		Merge(money.TypeMap()).
+//		Register(sql.NullString{}, nullStringMapping).
+//		Override(teamTypeMap).
+//		Build()
+//
+// A TypeMapBuilder is immutable: every method returns a new builder and leaves
+// its receiver as is, so a builder may be shared and extended separately.
+// Conflicting registrations are reported by Build.
+type TypeMapBuilder struct {
+	typeMap TypeMap
+	// The go types registered differently so far
+	conflicts map[reflect.Type]bool
+}
+
+// NewTypeMapBuilder returns a builder whose first layer is a copy of base,
+// which is typically GetDefaultTypeMap(). base may be nil.
+func NewTypeMapBuilder(base TypeMap) TypeMapBuilder {
+	return TypeMapBuilder{typeMap: base.Clone()}
+}
+
+// Register returns a builder that maps the type of instance by mapping.
+// It conflicts with a different mapping of the same type by previous layers.
+func (b TypeMapBuilder) Register(instance interface{}, mapping GqlOutputAndResolver) TypeMapBuilder {
+	return b.merge([]TypeMap{{reflect.TypeOf(instance): mapping}})
+}
+
+// Merge returns a builder that adds the mappings of typeMap. A go type that
+// typeMap maps differently than previous layers is a conflict.
+func (b TypeMapBuilder) Merge(typeMap TypeMap) TypeMapBuilder {
+	return b.merge([]TypeMap{typeMap})
+}
+
+// Override returns a builder that adds the mappings of typeMap, which take
+// precedence over the mappings of previous layers, and settle their
+// conflicts.
+func (b TypeMapBuilder) Override(typeMap TypeMap) TypeMapBuilder {
+	next := TypeMapBuilder{
+		typeMap:   b.typeMap.Override(typeMap),
+		conflicts: make(map[reflect.Type]bool, len(b.conflicts)),
+	}
+	for t := range b.conflicts {
+		if _, exists := typeMap[t]; !exists {
+			next.conflicts[t] = true
+		}
+	}
+	return next
+}
+
+// Build returns the type map built so far, which is a new copy every time.
+// If go types were registered differently, and not overridden later on, it
+// also returns a *TypeMapConflictError listing them. Each of them keeps its
+// first mapping.
+func (b TypeMapBuilder) Build() (TypeMap, error) {
+	typeMap := b.typeMap.Clone()
+	if len(b.conflicts) == 0 {
+		return typeMap, nil
+	}
+	err := &TypeMapConflictError{}
+	for t := range b.conflicts {
+		err.Types = append(err.Types, t)
+	}
+	sort.Slice(err.Types, func(i, j int) bool {
+		return err.Types[i].String() < err.Types[j].String()
+	})
+	return typeMap, err
+}
+
+// Add the mappings of typeMaps, recording the go types that they map
+// differently than the builder
+func (b TypeMapBuilder) merge(typeMaps []TypeMap) TypeMapBuilder {
+	next := TypeMapBuilder{
+		typeMap:   b.typeMap.Clone(),
+		conflicts: make(map[reflect.Type]bool, len(b.conflicts)),
+	}
+	for t := range b.conflicts {
+		next.conflicts[t] = true
+	}
+	for _, typeMap := range typeMaps {
+		for t, mapping := range typeMap {
+			if existing, exists := next.typeMap[t]; exists && !sameMapping(existing, mapping) {
+				next.conflicts[t] = true
+				continue
+			}
+			next.typeMap[t] = mapping
+		}
+	}
+	return next
+}
+
+// Whether two type map entries map a go type the same way, that is they are
+// the identical entry
+func sameMapping(a, b GqlOutputAndResolver) bool {
+	return a.Output == b.Output && a.Input == b.Input && sameResolver(a.Resolver, b.Resolver)
+}
+
+// Whether a and b are the same function value. Unlike reflect.Value.Pointer,
+// which is the code the function runs, this tells apart the closures of the
+// same function, which may capture different values.
+func sameResolver(a, b graphql.FieldResolveFn) bool {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&a)) == *(*unsafe.Pointer)(unsafe.Pointer(&b))
+}
@@ -7,10 +7,13 @@ import (
 )
 
 // GqlOutputAndResolver defines the value side of the TypeMap map.
-// It defines the couple of gql Output and gql Resolver
+// It defines the couple of gql Output and gql Resolver, and optionally the gql
+// Input to use in input types. When Input is not set, Output is used for
+// input types as well, provided it's a scalar or an enum.
 type GqlOutputAndResolver struct {
 	Output   graphql.Output
 	Resolver graphql.FieldResolveFn
+	Input    graphql.Input
 }
 
 // TypeMap defines a mapping b/w a go type and it's graphql Output type